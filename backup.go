@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupAgentTimeout is the age of its last update after which a backup agent is considered dead.
+const backupAgentTimeout = time.Minute
+
+// backupLagWarning is how far behind the last restorable version of a backup may be before it is shown in red.
+const backupLagWarning = 10 * time.Minute
+
+// failureRate returns the share of failed requests, in percent.
+func failureRate(failed, successful int64) float64 {
+	if failed+successful == 0 {
+		return 0
+	}
+	return float64(failed) * 100 / float64(failed+successful)
+}
+
+// ShowBackupScreen displays the backup agents and the backup tags of the backup layer.
+func ShowBackupScreen(status FdbStatus) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 12
+		COL2 = COL1 + 10
+		COL3 = COL2 + 9
+		COL4 = COL3 + 12
+		COL5 = COL4 + 12
+		COL6 = COL5 + 10
+		COL7 = COL6 + 10
+		COL8 = COL7 + 12
+		COL9 = COL8 + 10
+		COLA = COL9 + 10
+	)
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+	backup := &status.Cluster.Layers.Backup
+	now := float64(status.Cluster.ClusterControllerTimestamp)
+
+	// Agents and tags come and go, the whole screen is cleared
+	for y := 5; y < ScreenHeight-1; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+	if !status.Cluster.Layers.Valid {
+		SetColor("DarkGray")
+		WriteAtS(COL0, 5, "No backup layer status")
+		return
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 5, "Agents")
+	WriteAtS(COL0, 6, "Blob store")
+
+	SetColorIf(backup.InstancesRunning > 0, "White", "Red")
+	WriteAt(COL1, 5, "%d running, %d workers", backup.InstancesRunning, backup.TotalWorkers)
+	if backup.Paused {
+		SetColor("Yellow")
+		WriteAtS(COL5, 5, "PAUSED")
+	} else {
+		SetColor("Green")
+		WriteAtS(COL5, 5, "not paused")
+	}
+
+	blob := &backup.BlobRecentIo
+	SetColor("White")
+	WriteAt(COL1, 6, "%s/s", FriendlyBytes(blob.BytesPerSecond))
+	rate := failureRate(blob.RequestsFailed, blob.RequestsSuccessful)
+	SetColorIf(rate >= 1, "Red", "Gray")
+	WriteAt(COL5, 6, "%d requests, %d failed (%.1f%%) recently", blob.RequestsSuccessful+blob.RequestsFailed, blob.RequestsFailed, rate)
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 8, "Agent")
+	WriteAtS(COL1, 8, "Version")
+	WriteAtS(COL2, 8, "Workers")
+	WriteAtS(COL3, 8, "Main CPU s")
+	WriteAtS(COL4, 8, "Proc. CPU s")
+	WriteAtS(COL5, 8, "   Memory")
+	WriteAtS(COL6, 8, "      RSS")
+	WriteAtS(COL7, 8, "    Blob/s")
+	WriteAtS(COL8, 8, " Requests")
+	WriteAtS(COL9, 8, " Failed %")
+	WriteAtS(COLA, 8, "Updated")
+
+	var ids []string
+	for id := range backup.Instances {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	y := 9
+	for _, id := range ids {
+		if y >= ScreenHeight-1 {
+			break
+		}
+		instance := backup.Instances[id]
+		recent := &instance.BlobStats.Recent
+		age := time.Duration((now - instance.LastUpdated) * float64(time.Second))
+		dead := now > 0 && age > backupAgentTimeout
+
+		WriteAtS(0, y, emptyLine)
+		SetColorIf(dead, "Red", "White")
+		WriteAtS(COL0, y, trimMax(id, 11))
+		SetColor("Gray")
+		WriteAtS(COL1, y, trimMax(instance.Version, 9))
+		WriteAt(COL2, y, "%7d", instance.ConfiguredWorkers)
+		WriteAt(COL3, y, "%10.0f", instance.MainThreadCpuSeconds)
+		WriteAt(COL4, y, "%11.0f", instance.ProcessCpuSeconds)
+		SetColor(MapMemoryToColor(instance.MemoryUsage))
+		WriteAt(COL5, y, "%9s", FriendlyBytes(instance.MemoryUsage))
+		SetColor(MapMemoryToColor(instance.ResidentSize))
+		WriteAt(COL6, y, "%9s", FriendlyBytes(instance.ResidentSize))
+		SetColorIf(recent.BytesPerSecond > 0, "White", "DarkGray")
+		WriteAt(COL7, y, "%10s", FriendlyBytes(recent.BytesPerSecond))
+		SetColor("Gray")
+		WriteAt(COL8, y, "%9d", recent.RequestsSuccessful+recent.RequestsFailed)
+		rate := failureRate(recent.RequestsFailed, recent.RequestsSuccessful)
+		SetColorIf(rate >= 1, "Red", "Gray")
+		WriteAt(COL9, y, "%9.1f", rate)
+		SetColorIf(dead, "Red", "Gray")
+		if dead {
+			WriteAt(COLA, y, "%s ago, DEAD?", age.Round(time.Second))
+		} else {
+			WriteAt(COLA, y, "%s ago", age.Round(time.Second))
+		}
+		y++
+	}
+
+	y++
+	if y >= ScreenHeight-1 {
+		return
+	}
+	SetColor("DarkCyan")
+	WriteAtS(COL0, y, "Tag")
+	WriteAtS(COL1, y, "Status")
+	WriteAtS(COL4, y, "Restorable")
+	WriteAtS(COL5, y, " Behind s")
+	WriteAtS(COL6, y, "   Ranges")
+	WriteAtS(COL7, y, "      Logs")
+	WriteAtS(COL8, y, "Container")
+	y++
+
+	var tags []string
+	for tag := range backup.Tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	for _, name := range tags {
+		if y >= ScreenHeight-1 {
+			break
+		}
+		tag := backup.Tags[name]
+
+		WriteAtS(0, y, emptyLine)
+		SetColor("White")
+		WriteAtS(COL0, y, trimMax(name, 11))
+		SetColorIf(tag.RunningBackup, "Green", "Gray")
+		WriteAtS(COL1, y, trimMax(tag.CurrentStatus, COL4-COL1-1))
+		SetColorIf(tag.RunningBackup && !tag.RunningBackupIsRestorable, "Yellow", "Gray")
+		WriteAtS(COL4, y, fmt.Sprint(tag.RunningBackupIsRestorable))
+		SetColorIf(tag.LastRestorableSecondsBehind > backupLagWarning.Seconds(), "Red", "Gray")
+		WriteAt(COL5, y, "%9.1f", tag.LastRestorableSecondsBehind)
+		SetColor("Gray")
+		WriteAt(COL6, y, "%9s", FriendlyBytes(tag.RangeBytesWritten))
+		WriteAt(COL7, y, "%10s", FriendlyBytes(tag.MutationLogBytesWritten))
+		WriteAtS(COL8, y, trimMax(tag.CurrentContainer, ScreenWidth-COL8-1))
+		y++
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BatchSample is the json object written for every poll in batch mode.
+type BatchSample struct {
+	Time           time.Time `json:"time"`
+	Cluster        string    `json:"cluster"`
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+	Error          string    `json:"error,omitempty"`
+	HistoryMetric
+	Processes []ProcessSummary `json:"processes"`
+	Roles     []RoleSummary    `json:"roles"`
+}
+
+// RunBatch writes a json line to w for every status polled from the clusters,
+// and stops after the given number of iterations per cluster, if positive.
+// Every status is also handed to observe.
+func RunBatch(w io.Writer, clusters []*Cluster, interval time.Duration, iterations int, observe func(*StatusEvent)) error {
+	var (
+		enc = json.NewEncoder(w)
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		err error
+	)
+	for _, cluster := range clusters {
+		wg.Add(1)
+		go func(cluster *Cluster) {
+			defer wg.Done()
+
+			var (
+				lap   time.Time
+				count int
+			)
+			cluster.Poll(func() time.Duration { return interval }, func(ev *StatusEvent) bool {
+				observe(ev)
+				if lap.IsZero() {
+					lap = ev.when
+				}
+				sample := BatchSample{
+					Time:           ev.when,
+					Cluster:        cluster.Name,
+					ElapsedSeconds: ev.when.Sub(lap).Seconds(),
+					HistoryMetric:  NewHistoryMetric(ev.status, ev.when.Sub(lap)),
+					Processes:      SummarizeProcesses(ev.status),
+					Roles:          SummarizeRoles(ev.status),
+				}
+				if ev.err != nil {
+					sample.Error = ev.err.Error()
+				}
+				if ev.warning != nil {
+					log.Printf("%s: %v\n", cluster.Name, ev.warning)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					return false
+				}
+				if e := enc.Encode(&sample); e != nil {
+					err = errors.Wrap(e, "cannot write sample")
+					return false
+				}
+				count++
+				return iterations <= 0 || count < iterations
+			})
+		}(cluster)
+	}
+	wg.Wait()
+	return err
+}
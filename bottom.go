@@ -1,9 +1,47 @@
 package main
 
 import (
+	"fmt"
 	"strings"
 )
 
+// ScreenTab is a screen selectable from the bottom bar.
+type ScreenTab struct {
+	Mode  DisplayMode
+	Key   rune
+	Name  string
+	Label string
+}
+
+var ScreenTabs = []ScreenTab{
+	{Metrics, 'm', "metrics", " [M]etrics "},
+	{Transactions, 't', "transactions", " [T]ransactions "},
+	{Latency, 'l', "latency", " [L]atency "},
+	{Processes, 'p', "processes", " [P]rocesses "},
+	{Roles, 'r', "roles", " [R]roles "},
+	{Qos, 'k', "qos", " Rate[k]eeper "},
+	{Data, 'd', "data", " [D]ata "},
+	{Logs, 'g', "logs", " Lo[g]s "},
+	{Coordinators, 'a', "coordinators", " Coordin[a]tors "},
+	{Clients, 'n', "clients", " Clie[n]ts "},
+	{Backup, 'b', "backup", " [B]ackup "},
+	{Messages, 's', "messages", " Me[s]sages "},
+	{Faults, 'u', "faults", " Fa[u]lts "},
+	{Config, 'i', "config", " Conf[i]g "},
+	{ReadLatency, 'y', "read-latency", " Read Latenc[y] "},
+	{Overview, 'o', "overview", " [O]verview "},
+}
+
+// ScreenTabForKey returns the screen selected by a key.
+func ScreenTabForKey(key rune) (ScreenTab, bool) {
+	for _, tab := range ScreenTabs {
+		if tab.Key == key {
+			return tab, true
+		}
+	}
+	return ScreenTab{}, false
+}
+
 func RepaintBottomBar(mode DisplayMode) {
 	ScreenWidth, ScreenHeight := screen.Size()
 
@@ -11,19 +49,38 @@ func RepaintBottomBar(mode DisplayMode) {
 	emptyLine := strings.Repeat(" ", ScreenWidth)
 	WriteAtS(0, ScreenHeight-1, emptyLine)
 
-	SetColorIf(mode == Metrics, "Black", "White")
-	WriteAtS(0, ScreenHeight-1, " [M]etrics ")
-
-	SetColorIf(mode == Transactions, "Black", "White")
-	WriteAtS(11, ScreenHeight-1, " [T]ransactions ")
-
-	SetColorIf(mode == Latency, "Black", "White")
-	WriteAtS(27, ScreenHeight-1, " [L]atency ")
+	// When the labels do not fit, only the label of the current screen is shown in full
+	width := 0
+	for _, tab := range ScreenTabs {
+		width += len(tab.Label)
+	}
+	compact := width > ScreenWidth-20
 
-	SetColorIf(mode == Processes, "Black", "White")
-	WriteAtS(38, ScreenHeight-1, " [P]rocesses ")
+	x := 0
+	for _, tab := range ScreenTabs {
+		label := tab.Label
+		if compact && mode != tab.Mode {
+			label = fmt.Sprintf(" %c ", tab.Key)
+		}
+		SetColorIf(mode == tab.Mode, "Black", "White")
+		WriteAtS(x, ScreenHeight-1, label)
+		x += len(label)
+	}
 
-	SetColorIf(mode == Roles, "Black", "White")
-	WriteAtS(51, ScreenHeight-1, " [R]roles ")
+	if len(Clusters) > 1 {
+		x++
+		for i, cluster := range Clusters {
+			label := fmt.Sprintf(" %d:%s ", i+1, cluster.Name)
+			if cluster == ActiveCluster {
+				SetBackground("Cyan")
+				SetColor("Black")
+			} else {
+				SetBackground("DarkCyan")
+				SetColor("White")
+			}
+			WriteAtS(x, ScreenHeight-1, label)
+			x += len([]rune(label))
+		}
+	}
 	SetBackground("Black")
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ClientGroup is the clients of a log group connected with a client version.
+type ClientGroup struct {
+	LogGroup    string
+	Addresses   []string
+	MaxProtocol int
+}
+
+// olderProtocol returns true if the protocol version a, in hexadecimal, is older than b.
+func olderProtocol(a, b string) bool {
+	x, errA := strconv.ParseUint(a, 16, 64)
+	y, errB := strconv.ParseUint(b, 16, 64)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return x < y
+}
+
+// ShowClientsScreen displays the connected clients, grouped by client version and log group,
+// newest first. Versions older than the protocol of the cluster are highlighted, in red when
+// some clients support no newer version and would be cut off by an upgrade.
+func ShowClientsScreen(status FdbStatus) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 14
+		COL2 = COL1 + 20
+		COL3 = COL2 + 12
+		COL4 = COL3 + 14
+	)
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+	clients := &status.Cluster.Clients
+
+	WriteAtS(0, 5, emptyLine)
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 5, "Clients")
+	WriteAtS(COL2, 5, "Protocol")
+	SetColor("White")
+	WriteAt(COL1, 5, "%d", clients.Count)
+	WriteAtS(COL3, 5, status.Cluster.ProtocolVersion)
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 7, "Version")
+	WriteAtS(COL1, 7, "Protocol")
+	WriteAtS(COL2, 7, "   Clients")
+	WriteAtS(COL3, 7, "Max protocol")
+	WriteAtS(COL4, 7, "Log group / addresses")
+
+	versions := append(clients.SupportedVersions[:0:0], clients.SupportedVersions...)
+	sort.SliceStable(versions, func(i, j int) bool {
+		return olderProtocol(versions[j].ProtocolVersion, versions[i].ProtocolVersion)
+	})
+
+	y := 8
+	for _, v := range versions {
+		if y >= ScreenHeight-1 {
+			break
+		}
+		outdated := olderProtocol(v.ProtocolVersion, status.Cluster.ProtocolVersion)
+
+		WriteAtS(0, y, emptyLine)
+		switch {
+		case outdated && v.MaxProtocolCount > 0:
+			SetColor("Red")
+		case outdated:
+			SetColor("DarkYellow")
+		default:
+			SetColor("White")
+		}
+		WriteAtS(COL0, y, trimMax(v.ClientVersion, 13))
+		WriteAtS(COL1, y, trimMax(v.ProtocolVersion, 19))
+		WriteAt(COL2, y, "%10d", v.Count)
+		WriteAt(COL3, y, "%12d", v.MaxProtocolCount)
+		if outdated && v.MaxProtocolCount > 0 {
+			WriteAtS(COL4, y, "OUTDATED, no newer protocol supported by the max protocol clients")
+		} else if outdated {
+			WriteAtS(COL4, y, "older than the cluster")
+		}
+		y++
+
+		groups := make(map[string]*ClientGroup)
+		var names []string
+		for _, c := range v.ConnectedClients {
+			g, ok := groups[c.LogGroup]
+			if !ok {
+				g = &ClientGroup{LogGroup: c.LogGroup}
+				groups[c.LogGroup] = g
+				names = append(names, c.LogGroup)
+			}
+			g.Addresses = append(g.Addresses, c.Address)
+		}
+		for _, c := range v.MaxProtocolClients {
+			if g, ok := groups[c.LogGroup]; ok {
+				g.MaxProtocol++
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if y >= ScreenHeight-1 {
+				break
+			}
+			g := groups[name]
+			WriteAtS(0, y, emptyLine)
+			SetColor("Gray")
+			WriteAt(COL2, y, "%10d", len(g.Addresses))
+			SetColorIf(outdated && g.MaxProtocol > 0, "Red", "Gray")
+			WriteAt(COL3, y, "%12d", g.MaxProtocol)
+			SetColor("Gray")
+			WriteAtS(COL4, y, trimMax(g.LogGroup, 20))
+			SetColor("DarkGray")
+			WriteAtS(COL4+21, y, trimMax(strings.Join(g.Addresses, " "), ScreenWidth-COL4-22))
+			y++
+		}
+	}
+	for ; y < ScreenHeight-1; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Cluster is a monitored cluster, with its own poller and History.
+type Cluster struct {
+	Name   string
+	Source StatusSource
+
+	// Status is the last good status, taken at Updated, and Metric its metrics.
+	Status  FdbStatus
+	Metric  HistoryMetric
+	Updated time.Time
+	History []HistoryMetric
+
+	// ProcessHistory is the recent activity of every process, by address,
+	// and RoleHistory of every role, by RoleKey.
+	ProcessHistory map[string][]ProcessSample
+	RoleHistory    map[string][]RoleSample
+
+	// NewMessages are the keys of the messages that appeared with the last status.
+	NewMessages map[string]bool
+
+	// Recoveries are the changes of recovery state seen during the session.
+	Recoveries []RecoveryEvent
+
+	// ConfigChanges are the changes of the configuration seen during the session.
+	ConfigChanges []ConfigChange
+
+	// LastError is the error of the last failed poll, at LastErrorTime.
+	LastError     error
+	LastErrorTime time.Time
+	Errors        int
+
+	// Warning is a problem that does not fail the polls, like a damaged session or a recording that stopped.
+	Warning error
+
+	// Dropped counts the statuses lost because the event queue of the screen was full.
+	Dropped atomic.Int64
+
+	lap time.Time
+}
+
+// staleIntervals is the number of poll intervals without a new status after which it is stale.
+const staleIntervals = 5
+
+// Clusters are all the clusters monitored by fdbtop, in the order given on the command line.
+var Clusters []*Cluster
+
+// ActiveCluster is the cluster on screen.
+var ActiveCluster *Cluster
+
+func NewCluster(name string, source StatusSource) *Cluster {
+	return &Cluster{Name: name, Source: source}
+}
+
+// ClusterName returns the name of a cluster, from a "name=path" argument
+// or from the base name of the path without its extension.
+func ClusterName(arg string) (name string, path string) {
+	if p := strings.Index(arg, "="); p > 0 {
+		return arg[:p], arg[p+1:]
+	}
+	base := filepath.Base(arg)
+	return strings.TrimSuffix(base, filepath.Ext(base)), arg
+}
+
+// Poll polls the source of the cluster, see Poll.
+func (c *Cluster) Poll(interval func() time.Duration, fn func(*StatusEvent) bool) {
+	Poll(c.Source, interval, func(ev *StatusEvent) bool {
+		ev.cluster = c
+		return fn(ev)
+	})
+}
+
+// Update makes the status of an event the current status of the cluster.
+// A failed poll keeps the last good status, and is recorded as unavailable in the History.
+func (c *Cluster) Update(ev *StatusEvent) {
+	if c.lap.IsZero() {
+		c.lap = ev.when
+	}
+	if ev.warning != nil {
+		c.Warning = ev.warning
+	}
+	if ev.err != nil {
+		c.LastError = ev.err
+		c.LastErrorTime = ev.when
+		c.Errors++
+		c.History = AppendHistory(c.History, HistoryMetric{LocalTime: ev.when.Sub(c.lap)})
+		return
+	}
+	c.NewMessages = nil
+	if !c.Updated.IsZero() {
+		c.Recoveries = AppendRecovery(c.Recoveries, &c.Status, ev)
+		c.NewMessages = NewMessages(&c.Status, &ev.status)
+		c.ConfigChanges = AppendConfigChanges(c.ConfigChanges, &c.Status, ev)
+	}
+	c.Status = ev.status
+	c.Metric = NewHistoryMetric(ev.status, ev.when.Sub(c.lap))
+	c.Updated = ev.when
+	c.History = AppendHistory(c.History, c.Metric)
+	c.ProcessHistory = AppendProcessHistory(c.ProcessHistory, &ev.status)
+	c.RoleHistory = AppendRoleHistory(c.RoleHistory, &ev.status)
+}
+
+// Stale returns true when the status on screen is not current at now,
+// because the last poll failed or no status was received for a few intervals.
+func (c *Cluster) Stale(now time.Time) bool {
+	if c.Updated.IsZero() {
+		return false
+	}
+	return c.LastErrorTime.After(c.Updated) || now.Sub(c.Updated) > staleIntervals**interval
+}
+
+// Rewind forgets the History and the changes seen, before a replayed session is fed again.
+func (c *Cluster) Rewind() {
+	c.History = nil
+	c.ProcessHistory = nil
+	c.RoleHistory = nil
+	c.Recoveries = nil
+	c.ConfigChanges = nil
+	c.Updated = time.Time{}
+}
+
+// Reset clears the History, the elapsed time being counted from lap,
+// or from the next status if lap is zero.
+func (c *Cluster) Reset(lap time.Time) {
+	c.History = nil
+	c.ProcessHistory = nil
+	c.RoleHistory = nil
+	c.lap = lap
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxConfigChanges is the number of configuration changes kept per cluster.
+const maxConfigChanges = 100
+
+// ConfigField is a field of the database configuration, named after its status json key.
+type ConfigField struct {
+	Name  string
+	Value string
+}
+
+// ConfigChange is a change of a configuration field seen during the session.
+type ConfigChange struct {
+	When time.Time
+	Name string
+	Old  string
+	New  string
+}
+
+// ConfigFields returns the fields of the configuration of the database, in display order.
+func ConfigFields(status *FdbStatus) []ConfigField {
+	config := &status.Cluster.Configuration
+	i := func(x int64) string {
+		return strconv.FormatInt(x, 10)
+	}
+
+	var excluded []string
+	for _, s := range config.ExcludedServers {
+		excluded = append(excluded, s.Address)
+	}
+	sort.Strings(excluded)
+
+	return []ConfigField{
+		{"redundancy_mode", config.RedundancyMode},
+		{"storage_engine", config.StorageEngine},
+		{"usable_regions", i(config.UsableRegions)},
+		{"coordinators_count", i(config.CoordinatorsCount)},
+		{"log_spill", i(config.LogSpill)},
+		{"tenant_mode", config.TenantMode},
+		{"storage_migration_type", config.StorageMigrationType},
+		{"perpetual_storage_wiggle", i(config.PerpetualStorageWiggle)},
+		{"perpetual_storage_wiggle_engine", config.PerpetualStorageWiggleEngine},
+		{"perpetual_storage_wiggle_locality", config.PerpetualStorageWiggleLocality},
+		{"blob_granules_enabled", i(config.BlobGranulesEnabled)},
+		{"backup_worker_enabled", i(config.BackupWorkerEnabled)},
+		{"excluded_servers", strings.Join(excluded, " ")},
+	}
+}
+
+// AppendConfigChanges adds to changes the configuration fields that differ between
+// the previous status and the status of ev. A status without configuration, as polled
+// during a recovery, is not compared.
+func AppendConfigChanges(changes []ConfigChange, previous *FdbStatus, ev *StatusEvent) []ConfigChange {
+	if previous.Cluster.Configuration.RedundancyMode == "" || ev.status.Cluster.Configuration.RedundancyMode == "" {
+		return changes
+	}
+	before := ConfigFields(previous)
+	for i, field := range ConfigFields(&ev.status) {
+		if field.Value != before[i].Value {
+			changes = append(changes, ConfigChange{When: ev.when, Name: field.Name, Old: before[i].Value, New: field.Value})
+		}
+	}
+	if len(changes) > maxConfigChanges {
+		changes = changes[len(changes)-maxConfigChanges:]
+	}
+	return changes
+}
+
+// ShowConfigScreen displays the configuration of the database, the fields that changed
+// during the session being highlighted, and the log of the changes.
+func ShowConfigScreen(status FdbStatus, changes []ConfigChange) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 36
+		COL2 = COL0 + 22
+		COL3 = COL2 + 36
+	)
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+
+	changed := make(map[string]time.Time)
+	for _, c := range changes {
+		changed[c.Name] = c.When
+	}
+
+	y := 5
+	for _, field := range ConfigFields(&status) {
+		if y >= ScreenHeight-1 {
+			return
+		}
+		WriteAtS(0, y, emptyLine)
+		SetColor("DarkCyan")
+		WriteAtS(COL0, y, field.Name)
+
+		value := field.Value
+		if value == "" {
+			value = "-"
+		}
+		when, ok := changed[field.Name]
+		SetColorIf(ok, "Yellow", "White")
+		WriteAtS(COL1, y, trimMax(value, ScreenWidth-COL1-1))
+		if ok && COL1+len(value)+2 < ScreenWidth {
+			WriteAt(COL1+len(value)+2, y, "changed at %s", when.UTC().Format("15:04:05"))
+		}
+		y++
+	}
+
+	y++
+	if y >= ScreenHeight-1 {
+		return
+	}
+	SetColor("DarkCyan")
+	WriteAtS(COL0, y, "Changes during the session")
+	y++
+	if len(changes) == 0 {
+		SetColor("DarkGray")
+		WriteAtS(COL0, y, "none")
+	}
+	for i := len(changes) - 1; i >= 0 && y < ScreenHeight-1; i-- {
+		c := &changes[i]
+		WriteAtS(0, y, emptyLine)
+		SetColor("Gray")
+		WriteAtS(COL0, y, c.When.UTC().Format("2006-01-02 15:04:05"))
+		SetColor("Yellow")
+		WriteAtS(COL2, y, c.Name)
+		SetColor("White")
+		WriteAtS(COL3, y, trimMax(c.Old+" -> "+c.New, ScreenWidth-COL3-1))
+		y++
+	}
+}
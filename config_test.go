@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func configStatus(mode string, engine string) FdbStatus {
+	var status FdbStatus
+	status.Cluster.Configuration.RedundancyMode = mode
+	status.Cluster.Configuration.StorageEngine = engine
+	status.Cluster.Configuration.UsableRegions = 1
+	return status
+}
+
+func TestAppendConfigChanges(t *testing.T) {
+	previous := configStatus("double", "ssd-2")
+	ev := &StatusEvent{when: time.Unix(100, 0), status: configStatus("triple", "ssd-2")}
+
+	changes := AppendConfigChanges(nil, &previous, ev)
+	if len(changes) != 1 {
+		t.Fatalf("got %d changes, expected 1: %v", len(changes), changes)
+	}
+	c := changes[0]
+	if c.Name != "redundancy_mode" || c.Old != "double" || c.New != "triple" || !c.When.Equal(ev.when) {
+		t.Errorf("unexpected change %+v", c)
+	}
+}
+
+func TestAppendConfigChangesWithoutConfiguration(t *testing.T) {
+	good := configStatus("double", "ssd-2")
+	partial := configStatus("", "")
+
+	// A status without configuration, then the next good one, are not changes
+	changes := AppendConfigChanges(nil, &good, &StatusEvent{when: time.Unix(100, 0), status: partial})
+	changes = AppendConfigChanges(changes, &partial, &StatusEvent{when: time.Unix(101, 0), status: good})
+	if len(changes) != 0 {
+		t.Errorf("got %d changes, expected none: %v", len(changes), changes)
+	}
+}
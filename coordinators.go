@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CoordinatorsQuorum counts the coordinators reachable by fdbtop, and returns how many more
+// of them can be lost before the quorum is, negative when it is already lost.
+func CoordinatorsQuorum(status *FdbStatus) (reachable, total, margin int) {
+	coordinators := &status.Client.Coordinators
+	total = len(coordinators.Coordinators)
+	for _, c := range coordinators.Coordinators {
+		if c.Reachable {
+			reachable++
+		}
+	}
+	margin = reachable - (total/2 + 1)
+	if !coordinators.QuorumReachable && margin >= 0 {
+		margin = -1
+	}
+	return reachable, total, margin
+}
+
+// CoordinatorsColor is the color of the coordinators, red when the quorum is lost or
+// cannot lose another coordinator, yellow when some of them are unreachable.
+func CoordinatorsColor(reachable, total, margin int) string {
+	switch {
+	case margin <= 0:
+		return "Red"
+	case reachable < total:
+		return "Yellow"
+	default:
+		return "Green"
+	}
+}
+
+// ShowCoordinatorsScreen displays the reachability of every coordinator and of their quorum,
+// and the state of the local cluster file.
+func ShowCoordinatorsScreen(status FdbStatus) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 14
+		COL2 = COL1 + 24
+		COL3 = COL2 + 14
+		COL4 = COL3 + 20
+	)
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+	client := &status.Client
+
+	for y := 5; y <= 7; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 5, "Quorum")
+	WriteAtS(COL0, 6, "Margin")
+	WriteAtS(COL0, 7, "Cluster file")
+
+	reachable, total, margin := CoordinatorsQuorum(&status)
+	SetColor(CoordinatorsColor(reachable, total, margin))
+	if total == 0 {
+		// The status may come without the coordinators of the client
+		SetColor("Gray")
+		WriteAtS(COL1, 5, "unknown, no coordinators in the status")
+	} else if client.Coordinators.QuorumReachable {
+		WriteAt(COL1, 5, "reachable, %d of %d coordinators, %d needed", reachable, total, total/2+1)
+	} else {
+		WriteAt(COL1, 5, "UNREACHABLE, %d of %d coordinators, %d needed", reachable, total, total/2+1)
+	}
+	switch {
+	case total == 0:
+		WriteAtS(COL1, 6, "unknown")
+	case margin < 0:
+		WriteAtS(COL1, 6, "QUORUM LOST")
+	case margin == 0:
+		WriteAtS(COL1, 6, "cannot lose any coordinator")
+	default:
+		WriteAt(COL1, 6, "can lose %d more coordinator(s)", margin)
+	}
+
+	SetColorIf(client.ClusterFile.UpToDate, "Green", "Yellow")
+	if client.ClusterFile.UpToDate {
+		WriteAtS(COL1, 7, "up to date")
+	} else {
+		WriteAtS(COL1, 7, "OUT OF DATE")
+	}
+	SetColor("Gray")
+	WriteAtS(COL1+13, 7, trimMax(client.ClusterFile.Path, ScreenWidth-COL1-14))
+
+	processes := make(map[string]*FdbProcess)
+	for _, p := range status.Cluster.Processes {
+		p := p
+		processes[strings.TrimSuffix(p.Address, ":tls")] = &p
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 9, "#")
+	WriteAtS(COL1, 9, "Address")
+	WriteAtS(COL2, 9, "Reachable")
+	WriteAtS(COL3, 9, "Protocol")
+	WriteAtS(COL4, 9, "Process")
+
+	y := 10
+	for i, c := range client.Coordinators.Coordinators {
+		if y >= ScreenHeight-1 {
+			break
+		}
+		WriteAtS(0, y, emptyLine)
+
+		SetColor("Gray")
+		WriteAt(COL0, y, "%d", i+1)
+		WriteAtS(COL1, y, trimMax(c.Address, 23))
+		if c.Reachable {
+			SetColor("Green")
+			WriteAtS(COL2, y, "yes")
+		} else {
+			SetColor("Red")
+			WriteAtS(COL2, y, "NO")
+		}
+		SetColor("Gray")
+		WriteAtS(COL3, y, trimMax(c.Protocol, 19))
+
+		if p, ok := processes[strings.TrimSuffix(c.Address, ":tls")]; ok {
+			text := fmt.Sprintf("%s %s", p.ClassType, p.Locality.Machineid)
+			if p.Excluded {
+				text += " (excluded)"
+			}
+			SetColorIf(p.Excluded, "Yellow", "Gray")
+			WriteAtS(COL4, y, trimMax(text, ScreenWidth-COL4-1))
+		} else {
+			SetColor("DarkGray")
+			WriteAtS(COL4, y, "not in the cluster processes")
+		}
+		y++
+	}
+}
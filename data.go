@@ -0,0 +1,158 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// MovingBytes returns the bytes of data movement left, in flight or queued.
+func (m *HistoryMetric) MovingBytes() int64 {
+	return m.MovingInFlightBytes + m.MovingInQueueBytes
+}
+
+// EstimateDataMovement returns the rate at which data movement drained over the History,
+// in bytes per second, and the time left to complete it at that rate.
+// ok is false when data movement did not drain.
+func EstimateDataMovement(history []HistoryMetric) (rate float64, eta time.Duration, ok bool) {
+	var first, last *HistoryMetric
+	for i := range history {
+		if history[i].Available {
+			if first == nil {
+				first = &history[i]
+			}
+			last = &history[i]
+		}
+	}
+	if first == nil || last.LocalTime <= first.LocalTime {
+		return 0, 0, false
+	}
+
+	rate = float64(first.MovingBytes()-last.MovingBytes()) / (last.LocalTime - first.LocalTime).Seconds()
+	if rate <= 0 {
+		return rate, 0, false
+	}
+	return rate, time.Duration(float64(last.MovingBytes()) / rate * float64(time.Second)), true
+}
+
+// ShowDataScreen displays the state of data distribution, the team trackers,
+// and the History of the data movement left.
+func ShowDataScreen(status FdbStatus) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 12
+		COL2 = COL1 + 34
+		COL3 = COL2 + 30
+	)
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+	data := &status.Cluster.Data
+
+	for y := 5; y <= 8; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 5, "State")
+	WriteAtS(COL0, 6, "Shards")
+	WriteAtS(COL0, 7, "Moving")
+	WriteAtS(COL0, 8, "Drain rate")
+
+	SetColorIf(data.State.Healthy, "Green", "Yellow")
+	WriteAtS(COL1, 5, trimMax(data.State.Name, 32))
+	SetColorIf(data.State.MinReplicasRemaining > 1, "Gray", "Red")
+	WriteAt(COL2, 5, "Min replicas remaining: %d", data.State.MinReplicasRemaining)
+
+	SetColor("Gray")
+	WriteAt(COL1, 6, "%d x %s", data.PartitionsCount, FriendlyBytes(data.AveragePartitionSizeBytes))
+	WriteAt(COL2, 6, "Written: %s", FriendlyBytes(data.MovingData.TotalWrittenBytes))
+
+	moving := &data.MovingData
+	SetColorIf(moving.InFlightBytes+moving.InQueueBytes > 0, "White", "Gray")
+	WriteAt(COL1, 7, "%s in flight", FriendlyBytes(moving.InFlightBytes))
+	WriteAt(COL2, 7, "%s in queue", FriendlyBytes(moving.InQueueBytes))
+	WriteAt(COL3, 7, "Highest priority: %d", moving.HighestPriority)
+
+	rate, eta, ok := EstimateDataMovement(History)
+	SetColor("Gray")
+	if ok {
+		WriteAt(COL1, 8, "%s/s", FriendlyBytes(int64(rate)))
+		SetColor("White")
+		WriteAt(COL2, 8, "Done in: %s", eta.Round(time.Second))
+	} else if moving.InFlightBytes+moving.InQueueBytes > 0 {
+		WriteAtS(COL1, 8, "not draining")
+	} else {
+		WriteAtS(COL1, 8, "-")
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 10, "Team")
+	WriteAtS(COL1, 10, "State")
+	WriteAtS(COL2, 10, "Min replicas")
+	WriteAtS(COL2+14, 10, "Unhealthy servers")
+	WriteAtS(COL3+6, 10, "In flight")
+
+	y := 11
+	for _, tracker := range data.TeamTrackers {
+		if y >= ScreenHeight-1 {
+			break
+		}
+		WriteAtS(0, y, emptyLine)
+		SetColor("Gray")
+		if tracker.Primary {
+			WriteAtS(COL0, y, "primary")
+		} else {
+			WriteAtS(COL0, y, "remote")
+		}
+		SetColorIf(tracker.State.Healthy, "Green", "Yellow")
+		WriteAtS(COL1, y, trimMax(tracker.State.Name, 32))
+		SetColorIf(tracker.State.MinReplicasRemaining > 1, "Gray", "Red")
+		WriteAt(COL2, y, "%12d", tracker.State.MinReplicasRemaining)
+		SetColorIf(tracker.UnhealthyServers > 0, "Red", "Gray")
+		WriteAt(COL2+14, y, "%17d", tracker.UnhealthyServers)
+		SetColor("Gray")
+		WriteAt(COL3+6, y, "%9s", FriendlyBytes(tracker.InFlightBytes))
+		y++
+	}
+
+	// The History below moves up and down with the number of team trackers
+	for row := y; row < ScreenHeight-1; row++ {
+		WriteAtS(0, row, emptyLine)
+	}
+
+	y++
+	if y >= ScreenHeight-1 {
+		return
+	}
+	SetColor("DarkCyan")
+	WriteAtS(COL0, y, "Elapsed")
+	WriteAtS(COL1, y, "Moving data left")
+	WriteAtS(COL1+55, y, "In flight")
+	WriteAtS(COL1+67, y, "In queue")
+
+	maxMoving := GetMax(History, func(m HistoryMetric) float64 { return float64(m.MovingBytes()) })
+	SetColor("DarkGreen")
+	WriteAt(COL1+17, y, "%33s", FriendlyBytes(int64(maxMoving)))
+
+	y += 2 + len(History) - 1
+	for _, metric := range History {
+		if y < ScreenHeight-1 {
+			SetColor("DarkGray")
+			WriteAt(COL0, y, "%9s | %10s %40s | %10s %10s |", TimeSpanInSecondsWithRounding(metric.LocalTime.Seconds()), "", "", "", "")
+
+			if metric.Available {
+				SetColor(MapQueueSizeToColor(float64(metric.MovingBytes())))
+				WriteAt(COL1, y, "%10s", FriendlyBytes(metric.MovingBytes()))
+				SetColor("Green")
+				WriteAtS(COL1+11, y, strings.Repeat("|", Bar(float64(metric.MovingBytes()), maxMoving, MAX_RW_WIDTH)))
+				SetColor("Gray")
+				WriteAt(COL1+54, y, "%10s", FriendlyBytes(metric.MovingInFlightBytes))
+				WriteAt(COL1+65, y, "%10s", FriendlyBytes(metric.MovingInQueueBytes))
+			} else {
+				SetColor("DarkRed")
+				WriteAt(COL1, y, "%10s", "x")
+			}
+		}
+		y--
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// detailAddress is the address of the process shown in detail over the Processes and Roles screens, if any.
+var detailAddress string
+
+// detailScroll is the scroll position of the process detail.
+var detailScroll = &Scroll{}
+
+// detailLine is a line of the process detail, a section title or a labeled value.
+type detailLine struct {
+	title bool
+	label string
+	value string
+	color string
+}
+
+// OpenProcessDetail shows the detail of the process of a row of the Processes or Roles screens,
+// identified by its address, followed by '#' and the role on the Roles screen.
+func OpenProcessDetail(key string) {
+	address, _, _ := strings.Cut(key, "#")
+	detailAddress = address
+	detailScroll.Offset = 0
+}
+
+// HandleDetailKey opens the detail of the selected process with Enter, closes it with Enter, Esc or Backspace,
+// and returns false for any other key.
+func HandleDetailKey(ev *tcell.EventKey, mode DisplayMode) bool {
+	if detailAddress != "" {
+		switch ev.Key() {
+		case tcell.KeyEnter, tcell.KeyEscape, tcell.KeyBackspace, tcell.KeyBackspace2:
+			detailAddress = ""
+			return true
+		}
+		return false
+	}
+	if scroll, ok := Scrolls[mode]; ok && ev.Key() == tcell.KeyEnter && scroll.Cursor != "" {
+		OpenProcessDetail(scroll.Cursor)
+		return true
+	}
+	return false
+}
+
+// ProcessDetail returns the lines of the detail of a process: all its fields and those of its roles,
+// its messages and the sparklines of its recent activity and of the activity of its roles.
+func ProcessDetail(proc *FdbProcess, samples []ProcessSample, roleHistory map[string][]RoleSample, sparkWidth int) []detailLine {
+	var lines []detailLine
+	title := func(text string) {
+		if len(lines) > 0 {
+			lines = append(lines, detailLine{})
+		}
+		lines = append(lines, detailLine{title: true, label: text})
+	}
+	field := func(label string, color string, format string, args ...interface{}) {
+		lines = append(lines, detailLine{label: label, value: fmt.Sprintf(format, args...), color: color})
+	}
+	spark := func(label string, value string, values []float64) {
+		arrow, _ := Trend(values)
+		field(label, "Green", "%10s  %s %s", value, arrow, Sparkline(values, sparkWidth))
+	}
+	colorIf := func(v bool, c1 string, c2 string) string {
+		if v {
+			return c1
+		}
+		return c2
+	}
+
+	title("Process")
+	field("Class", "White", "%s (%s)", proc.ClassType, proc.ClassSource)
+	field("Command line", "Gray", "%s", proc.CommandLine)
+	field("Version", "White", "%s", proc.Version)
+	field("Uptime", "White", "%s", time.Duration(proc.UptimeSeconds)*time.Second)
+	field("Excluded", colorIf(proc.Excluded, "Red", "White"), "%t", proc.Excluded)
+	field("Fault domain", "White", "%s", proc.FaultDomain)
+	field("Machine", "White", "%s", proc.MachineId)
+	field("Locality", "White", "machineid=%s processid=%s zoneid=%s dcid=%s data_hall=%s",
+		proc.Locality.Machineid, proc.Locality.Processid, proc.Locality.Zoneid, proc.Locality.Dcid, proc.Locality.DataHall)
+	field("Run loop busy", "White", "%.1f%%", proc.RunLoopBusy*100)
+	field("CPU", "White", "%.1f%% of a core", proc.Cpu.UsageCores*100)
+
+	title("Memory")
+	field("Used", "White", "%s", FriendlyBytes(proc.Memory.UsedBytes))
+	field("Unused allocated", "White", "%s", FriendlyBytes(proc.Memory.UnusedAllocatedMemory))
+	field("Available", "White", "%s", FriendlyBytes(proc.Memory.AvailableBytes))
+	field("RSS", "White", "%s", FriendlyBytes(proc.Memory.RssBytes))
+	field("Limit", "White", "%s", FriendlyBytes(proc.Memory.LimitBytes))
+
+	title("Disk")
+	field("Busy", "White", "%.1f%%", proc.Disk.Busy*100)
+	field("Free / total", "White", "%s / %s", FriendlyBytes(proc.Disk.FreeBytes), FriendlyBytes(proc.Disk.TotalBytes))
+	field("Reads", "White", "%.1f Hz, counter %d, %.0f sectors", proc.Disk.Reads.Hz, proc.Disk.Reads.Counter, proc.Disk.Reads.Sectors)
+	field("Writes", "White", "%.1f Hz, counter %d, %.0f sectors", proc.Disk.Writes.Hz, proc.Disk.Writes.Counter, proc.Disk.Writes.Sectors)
+
+	network := &proc.Network
+	title("Network")
+	field("Received / sent", "White", "%.2f / %.2f Mbps", network.MegabitsReceived.Hz, network.MegabitsSent.Hz)
+	field("Connections", "White", "%d", network.CurrentConnections)
+	field("Established / closed", "White", "%.1f / %.1f Hz", network.ConnectionsEstablished.Hz, network.ConnectionsClosed.Hz)
+	field("Connection errors", colorIf(network.ConnectionErrors.Hz > 0, "Red", "White"), "%.1f Hz", network.ConnectionErrors.Hz)
+	field("TLS policy failures", colorIf(network.TlsPolicyFailures.Hz > 0, "Red", "White"), "%.1f Hz", network.TlsPolicyFailures.Hz)
+
+	title(fmt.Sprintf("Activity, last %d polls", len(samples)))
+	last := NewProcessSample(proc)
+	spark("CPU", fmt.Sprintf("%.1f%%", last.Cpu*100), ProcessSeries(samples, func(s *ProcessSample) float64 { return s.Cpu }))
+	spark("Memory", FriendlyBytes(last.Memory), ProcessSeries(samples, func(s *ProcessSample) float64 { return float64(s.Memory) }))
+	spark("Disk busy", fmt.Sprintf("%.1f%%", last.DiskBusy*100), ProcessSeries(samples, func(s *ProcessSample) float64 { return s.DiskBusy }))
+	spark("Received", fmt.Sprintf("%.2f Mbps", last.Recv), ProcessSeries(samples, func(s *ProcessSample) float64 { return s.Recv }))
+	spark("Sent", fmt.Sprintf("%.2f Mbps", last.Sent), ProcessSeries(samples, func(s *ProcessSample) float64 { return s.Sent }))
+	spark("Queue size", FriendlyBytes(last.QueueSize), ProcessSeries(samples, func(s *ProcessSample) float64 { return float64(s.QueueSize) }))
+
+	title("Messages")
+	if len(proc.Messages) == 0 {
+		field("", "DarkGray", "none")
+	}
+	for _, m := range proc.Messages {
+		message := StatusMessage{proc.Address, m}
+		field(m.Name, message.Severity().Color(), "%s", m.Description)
+	}
+
+	for i := range proc.Roles {
+		role := &proc.Roles[i]
+		title(fmt.Sprintf("Role %s %s", role.Role, role.Id))
+		lines = append(lines, roleDetail(role)...)
+
+		// Only the activity a role has is charted, the other series being all zeros
+		roleSamples := roleHistory[RoleKey(proc, role)]
+		last := NewRoleSample(role)
+		for _, series := range []struct {
+			label string
+			value string
+			get   func(s *RoleSample) float64
+		}{
+			{"trend queue size", FriendlyBytes(last.QueueSize), func(s *RoleSample) float64 { return float64(s.QueueSize) }},
+			{"trend data lag", fmt.Sprintf("%.2fs", last.DataLag), func(s *RoleSample) float64 { return s.DataLag }},
+			{"trend durability lag", fmt.Sprintf("%.2fs", last.DurabilityLag), func(s *RoleSample) float64 { return s.DurabilityLag }},
+			{"trend bytes queried", FriendlyBytes(int64(last.Queried)) + "/s", func(s *RoleSample) float64 { return s.Queried }},
+			{"trend mutation bytes", FriendlyBytes(int64(last.Mutation)) + "/s", func(s *RoleSample) float64 { return s.Mutation }},
+		} {
+			values := RoleSeries(roleSamples, series.get)
+			for _, v := range values {
+				if v != 0 {
+					spark(series.label, series.value, values)
+					break
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// roleDetail returns the fields of a role that are set, from its json form,
+// counters being shown with their rate and roughness.
+func roleDetail(role *FdbRole) []detailLine {
+	data, err := json.Marshal(role)
+	if err != nil {
+		return []detailLine{{label: "error", value: err.Error(), color: "Red"}}
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return []detailLine{{label: "error", value: err.Error(), color: "Red"}}
+	}
+
+	number := func(v interface{}) float64 {
+		f, _ := v.(float64)
+		return f
+	}
+
+	var lines []detailLine
+	var walk func(prefix string, fields map[string]interface{})
+	walk = func(prefix string, fields map[string]interface{}) {
+		var keys []string
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			name := prefix + key
+			switch v := fields[key].(type) {
+			case map[string]interface{}:
+				if hz, ok := v["hz"]; ok {
+					if number(hz) != 0 || number(v["counter"]) != 0 {
+						lines = append(lines, detailLine{label: name, color: "White",
+							value: fmt.Sprintf("%12.1f Hz  counter %.0f  roughness %.3f", number(hz), number(v["counter"]), number(v["roughness"]))})
+					}
+				} else {
+					walk(name+".", v)
+				}
+			case float64:
+				if v == 0 {
+					continue
+				}
+				value := strconv.FormatFloat(v, 'f', -1, 64)
+				if strings.HasSuffix(key, "_bytes") {
+					value += " (" + FriendlyBytes(int64(v)) + ")"
+				}
+				lines = append(lines, detailLine{label: name, value: value, color: "White"})
+			case string:
+				if v != "" && key != "role" && key != "id" {
+					lines = append(lines, detailLine{label: name, value: v, color: "White"})
+				}
+			}
+		}
+	}
+	walk("", fields)
+	return lines
+}
+
+// ShowProcessDetail displays the detail of the process at detailAddress.
+func ShowProcessDetail(status FdbStatus, history map[string][]ProcessSample, roleHistory map[string][]RoleSample) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 40
+
+		SPARK_WIDTH = 60
+	)
+
+	ScreenWidth, _ := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+
+	WriteAtS(0, 5, emptyLine)
+	SetColor("Cyan")
+	WriteAtS(COL0, 5, detailAddress)
+	SetColor("DarkGray")
+	WriteAtS(COL0+len(detailAddress)+2, 5, "[Esc] back to the list")
+
+	var proc *FdbProcess
+	for _, p := range status.Cluster.Processes {
+		if p.Address == detailAddress {
+			proc = &p
+			break
+		}
+	}
+
+	detailScroll.Begin(7)
+	var lines []detailLine
+	if proc == nil {
+		lines = []detailLine{{value: "The process is no longer in the status", color: "Red"}}
+	} else {
+		lines = ProcessDetail(proc, history[detailAddress], roleHistory, SPARK_WIDTH)
+	}
+	for row, line := range lines {
+		y, ok := detailScroll.Line(row)
+		if !ok {
+			continue
+		}
+		WriteAtS(0, y, emptyLine)
+		if line.color == "" && !line.title {
+			continue
+		}
+		if line.title {
+			SetColor("Cyan")
+			WriteAtS(COL0, y, line.label)
+			continue
+		}
+		SetColor("DarkCyan")
+		WriteAtS(COL0+2, y, trimMax(line.label, COL1-COL0-3))
+		SetColor(line.color)
+		WriteAtS(COL1, y, trimMax(line.value, ScreenWidth-COL1-2))
+	}
+	detailScroll.End(len(lines))
+}
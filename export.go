@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// historyColumns is the header of exported History, new columns must be added at the end.
+var historyColumns = []string{
+	"elapsed_seconds",
+	"server_time",
+	"timestamp",
+	"available",
+	"read_version",
+	"reads_per_second",
+	"writes_per_second",
+	"written_bytes_per_second",
+	"transactions_started",
+	"transactions_committed",
+	"transactions_conflicted",
+	"latency_commit",
+	"latency_read",
+	"latency_start",
+	"tps_limit",
+	"released_tps",
+	"batch_tps_limit",
+	"batch_released_tps",
+	"moving_in_flight_bytes",
+	"moving_in_queue_bytes",
+	"fault_tolerance_availability",
+	"fault_tolerance_data",
+	"min_replicas_remaining",
+	"full_replication",
+	"degraded_processes",
+	"storage_read_p99_median",
+	"storage_read_p99_worst",
+}
+
+func historyRow(m *HistoryMetric) []string {
+	f := func(x float64) string {
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	// Unavailable samples have no server time
+	serverTime := ""
+	if m.Timestamp != 0 {
+		serverTime = time.Unix(m.Timestamp, 0).UTC().Format(time.RFC3339)
+	}
+	return []string{
+		f(m.LocalTime.Seconds()),
+		serverTime,
+		strconv.FormatInt(m.Timestamp, 10),
+		strconv.FormatBool(m.Available),
+		strconv.FormatInt(m.ReadVersion, 10),
+		f(m.ReadsPerSecond),
+		f(m.WritesPerSecond),
+		f(m.WrittenBytesPerSecond),
+		f(m.TransStarted),
+		f(m.TransCommitted),
+		f(m.TransConflicted),
+		f(m.LatencyCommit),
+		f(m.LatencyRead),
+		f(m.LatencyStart),
+		f(m.TpsLimit),
+		f(m.ReleasedTps),
+		f(m.BatchTpsLimit),
+		f(m.BatchReleasedTps),
+		strconv.FormatInt(m.MovingInFlightBytes, 10),
+		strconv.FormatInt(m.MovingInQueueBytes, 10),
+		strconv.FormatInt(m.ToleranceAvailability, 10),
+		strconv.FormatInt(m.ToleranceData, 10),
+		strconv.FormatInt(m.MinReplicasRemaining, 10),
+		strconv.FormatBool(m.FullReplication),
+		strconv.FormatInt(m.DegradedProcesses, 10),
+		f(m.ReadP99Median),
+		f(m.ReadP99Worst),
+	}
+}
+
+// ExportComma returns the separator for an export format, "csv" or "tsv".
+// An empty format is guessed from the extension of the file.
+func ExportComma(format string, path string) (rune, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	switch format {
+	case "tsv":
+		return '\t', nil
+	case "csv", "":
+		return ',', nil
+	}
+	return 0, errors.Errorf("unknown export format %q", format)
+}
+
+// HistoryWriter writes HistoryMetric rows as CSV or TSV.
+type HistoryWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func NewHistoryWriter(w io.Writer, comma rune) *HistoryWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	return &HistoryWriter{w: cw}
+}
+
+func (h *HistoryWriter) Write(metric *HistoryMetric) error {
+	if !h.header {
+		if err := h.w.Write(historyColumns); err != nil {
+			return errors.Wrap(err, "cannot write header")
+		}
+		h.header = true
+	}
+	if err := h.w.Write(historyRow(metric)); err != nil {
+		return errors.Wrap(err, "cannot write row")
+	}
+	h.w.Flush()
+	return h.w.Error()
+}
+
+// ExportHistory writes the History on screen to a new file named after the current time.
+func ExportHistory(format string) (string, error) {
+	if format == "" {
+		format = "csv"
+	}
+	comma, err := ExportComma(format, "")
+	if err != nil {
+		return "", err
+	}
+	// Exports of the same second are numbered rather than overwritten
+	name := "fdbtop-" + time.Now().Format("20060102-150405")
+	path := name + "." + format
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	for n := 2; os.IsExist(err); n++ {
+		path = fmt.Sprintf("%s-%d.%s", name, n, format)
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "cannot create export file")
+	}
+
+	w := NewHistoryWriter(file, comma)
+	for i := range History {
+		if err := w.Write(&History[i]); err != nil {
+			file.Close()
+			return "", err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return "", errors.Wrap(err, "cannot write export file")
+	}
+	return path, nil
+}
+
+// RunExport writes a row to w for every status read from the source,
+// and stops after the given number of iterations, if positive.
+func RunExport(w io.Writer, comma rune, source StatusSource, interval time.Duration, iterations int) error {
+	var (
+		hw    = NewHistoryWriter(w, comma)
+		lap   time.Time
+		count int
+		err   error
+	)
+	Poll(source, func() time.Duration { return interval }, func(ev *StatusEvent) bool {
+		if lap.IsZero() {
+			lap = ev.when
+		}
+		metric := NewHistoryMetric(ev.status, ev.when.Sub(lap))
+		if err = hw.Write(&metric); err != nil {
+			return false
+		}
+		count++
+		return iterations <= 0 || count < iterations
+	})
+	return err
+}
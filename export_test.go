@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestHistoryRowUnavailable(t *testing.T) {
+	row := historyRow(&HistoryMetric{})
+	if row[1] != "" {
+		t.Errorf("got server time %q for an unavailable sample, expected none", row[1])
+	}
+	row = historyRow(&HistoryMetric{Timestamp: 1700000000, Available: true})
+	if row[1] != "2023-11-14T22:13:20Z" {
+		t.Errorf("got server time %q", row[1])
+	}
+}
+
+func TestExportHistoryTwice(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(dir)
+
+	History = []HistoryMetric{{Available: true}}
+	defer func() { History = nil }()
+	first, err := ExportHistory("csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ExportHistory("csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("both exports were written to %s", first)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Exporter serves the last polled status of every cluster as prometheus metrics,
+// labelled with the name of the cluster.
+type Exporter struct {
+	mu       sync.Mutex
+	clusters map[string]*exportedCluster
+}
+
+type exportedCluster struct {
+	status  FdbStatus
+	when    time.Time
+	updated time.Time
+	polls   int64
+	errors  int64
+}
+
+func NewExporter() *Exporter {
+	return &Exporter{clusters: make(map[string]*exportedCluster)}
+}
+
+// Update replaces the status of a cluster served by the exporter, a failed poll keeps the last good status.
+func (e *Exporter) Update(ev *StatusEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.clusters[ev.cluster.Name]
+	if !ok {
+		c = &exportedCluster{}
+		e.clusters[ev.cluster.Name] = c
+	}
+	c.when = ev.when
+	c.polls++
+	if ev.err != nil {
+		c.errors++
+		return
+	}
+	c.status = ev.status
+	c.updated = ev.when
+}
+
+// Serve starts serving /metrics on the given address, in the background.
+func (e *Exporter) Serve(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrap(err, "cannot listen for prometheus")
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	go func() {
+		if err := http.Serve(l, mux); err != nil {
+			log.Printf("prometheus: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	names := make([]string, 0, len(e.clusters))
+	for name := range e.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var p PromWriter
+	for _, name := range names {
+		e.clusters[name].collect(&p, name)
+	}
+	e.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := p.WriteTo(w); err != nil {
+		log.Printf("prometheus: %v\n", err)
+	}
+}
+
+func (e *exportedCluster) collect(w *PromWriter, name string) {
+	p := w.WithLabels("cluster", name)
+	p.Counter("fdbtop_polls_total", "Number of status polls.", float64(e.polls))
+	p.Counter("fdbtop_poll_errors_total", "Number of failed status polls.", float64(e.errors))
+	p.Gauge("fdbtop_last_poll_timestamp_seconds", "Local time of the last status poll.", float64(e.when.UnixNano())/1e9)
+	if e.updated.IsZero() {
+		return
+	}
+	p.Gauge("fdbtop_last_success_timestamp_seconds", "Local time of the last successful status poll.", float64(e.updated.UnixNano())/1e9)
+
+	status := &e.status
+	cluster := &status.Cluster
+	p.Gauge("fdb_database_available", "Whether the database is available.", boolValue(cluster.DatabaseAvailable))
+	p.Gauge("fdb_read_version", "Read version of the last poll.", float64(status.ReadVersion))
+
+	p.Gauge("fdb_workload_reads_hz", "Read operations per second.", cluster.Workload.Operations.Reads.Hz)
+	p.Gauge("fdb_workload_writes_hz", "Write operations per second.", cluster.Workload.Operations.Writes.Hz)
+	p.Gauge("fdb_workload_read_bytes_hz", "Bytes read per second.", cluster.Workload.Bytes.Read.Hz)
+	p.Gauge("fdb_workload_written_bytes_hz", "Bytes written per second.", cluster.Workload.Bytes.Written.Hz)
+	p.Gauge("fdb_workload_transactions_started_hz", "Transactions started per second.", cluster.Workload.Transactions.Started.Hz)
+	p.Gauge("fdb_workload_transactions_committed_hz", "Transactions committed per second.", cluster.Workload.Transactions.Committed.Hz)
+	p.Gauge("fdb_workload_transactions_conflicted_hz", "Transactions conflicted per second.", cluster.Workload.Transactions.Conflicted.Hz)
+
+	probe := &cluster.LatencyProbe
+	p.Gauge("fdb_latency_probe_commit_seconds", "Latency probe of a commit.", probe.CommitSeconds)
+	p.Gauge("fdb_latency_probe_read_seconds", "Latency probe of a read.", probe.ReadSeconds)
+	p.Gauge("fdb_latency_probe_transaction_start_seconds", "Latency probe of a transaction start.", probe.TransactionStartSeconds)
+	p.Gauge("fdb_latency_probe_batch_priority_transaction_start_seconds", "Latency probe of a batch priority transaction start.", probe.BatchPriorityTransactionStartSeconds)
+	p.Gauge("fdb_latency_probe_immediate_priority_transaction_start_seconds", "Latency probe of an immediate priority transaction start.", probe.ImmediatePriorityTransactionStartSeconds)
+
+	qos := &cluster.Qos
+	p.Gauge("fdb_qos_transactions_per_second_limit", "Transaction rate limit set by ratekeeper.", qos.TransactionsPerSecondLimit)
+	p.Gauge("fdb_qos_released_transactions_per_second", "Transactions released per second.", qos.ReleasedTransactionsPerSecond)
+	p.Gauge("fdb_qos_batch_transactions_per_second_limit", "Batch priority transaction rate limit set by ratekeeper.", qos.BatchTransactionsPerSecondLimit)
+	p.Gauge("fdb_qos_batch_released_transactions_per_second", "Batch priority transactions released per second.", qos.BatchReleasedTransactionsPerSecond)
+	p.Gauge("fdb_qos_worst_queue_bytes_log_server", "Largest queue of a log server.", float64(qos.WorstQueueBytesLogServer))
+	p.Gauge("fdb_qos_worst_queue_bytes_storage_server", "Largest queue of a storage server.", float64(qos.WorstQueueBytesStorageServer))
+	p.Gauge("fdb_qos_limiting_queue_bytes_storage_server", "Storage server queue limiting the rate.", float64(qos.LimitingQueueBytesStorageServer))
+	p.Gauge("fdb_qos_worst_data_lag_storage_server_seconds", "Largest data lag of a storage server.", qos.WorstDataLagStorageServer.Seconds)
+	p.Gauge("fdb_qos_limiting_data_lag_storage_server_seconds", "Storage server data lag limiting the rate.", qos.LimitingDataLagStorageServer.Seconds)
+	p.Gauge("fdb_qos_worst_durability_lag_storage_server_seconds", "Largest durability lag of a storage server.", qos.WorstDurabilityLagStorageServer.Seconds)
+	p.Gauge("fdb_qos_limiting_durability_lag_storage_server_seconds", "Storage server durability lag limiting the rate.", qos.LimitingDurabilityLagStorageServer.Seconds)
+	p.Gauge("fdb_qos_performance_limited_by", "Reason limiting the transaction rate.", 1, "reason", qos.PerformanceLimitedBy.Name)
+	p.Gauge("fdb_qos_batch_performance_limited_by", "Reason limiting the batch priority transaction rate.", 1, "reason", qos.BatchPerformanceLimitedBy.Name)
+
+	p.Gauge("fdb_data_total_kv_size_bytes", "Logical size of the key/value pairs.", float64(cluster.Data.TotalKvSizeBytes))
+	p.Gauge("fdb_data_total_disk_used_bytes", "Disk space used by the database.", float64(cluster.Data.TotalDiskUsedBytes))
+	p.Gauge("fdb_data_partitions_count", "Number of shards.", float64(cluster.Data.PartitionsCount))
+	p.Gauge("fdb_data_moving_in_flight_bytes", "Bytes of data being moved.", float64(cluster.Data.MovingData.InFlightBytes))
+	p.Gauge("fdb_data_moving_in_queue_bytes", "Bytes of data queued to be moved.", float64(cluster.Data.MovingData.InQueueBytes))
+
+	ids := make([]string, 0, len(cluster.Processes))
+	for id := range cluster.Processes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		proc := cluster.Processes[id]
+		labels := []string{"machine", proc.MachineId, "address", proc.Address, "class", proc.ClassType}
+		p.Gauge("fdb_process_cpu_usage_cores", "CPU cores used by the process.", proc.Cpu.UsageCores, labels...)
+		p.Gauge("fdb_process_run_loop_busy", "Fraction of time the run loop was busy.", proc.RunLoopBusy, labels...)
+		p.Gauge("fdb_process_memory_used_bytes", "Memory used by the process.", float64(proc.Memory.UsedBytes), labels...)
+		p.Gauge("fdb_process_memory_limit_bytes", "Memory limit of the process.", float64(proc.Memory.LimitBytes), labels...)
+		p.Gauge("fdb_process_memory_rss_bytes", "Resident memory of the process.", float64(proc.Memory.RssBytes), labels...)
+		p.Gauge("fdb_process_disk_busy", "Fraction of time the disk was busy.", proc.Disk.Busy, labels...)
+		p.Gauge("fdb_process_disk_free_bytes", "Free space of the disk.", float64(proc.Disk.FreeBytes), labels...)
+		p.Gauge("fdb_process_disk_total_bytes", "Size of the disk.", float64(proc.Disk.TotalBytes), labels...)
+		p.Gauge("fdb_process_disk_reads_hz", "Disk reads per second.", proc.Disk.Reads.Hz, labels...)
+		p.Gauge("fdb_process_disk_writes_hz", "Disk writes per second.", proc.Disk.Writes.Hz, labels...)
+		p.Gauge("fdb_process_network_megabits_received_hz", "Megabits received per second.", proc.Network.MegabitsReceived.Hz, labels...)
+		p.Gauge("fdb_process_network_megabits_sent_hz", "Megabits sent per second.", proc.Network.MegabitsSent.Hz, labels...)
+		p.Gauge("fdb_process_network_current_connections", "Open connections.", float64(proc.Network.CurrentConnections), labels...)
+		p.Gauge("fdb_process_uptime_seconds", "Uptime of the process.", proc.UptimeSeconds, labels...)
+		p.Gauge("fdb_process_excluded", "Whether the process is excluded.", boolValue(proc.Excluded), labels...)
+
+		for _, role := range proc.Roles {
+			if role.Role != StorageRoleMetrics && role.Role != LogRoleMetrics {
+				continue
+			}
+			labels := []string{"machine", proc.MachineId, "address", proc.Address, "class", proc.ClassType, "role", role.Role, "id", role.Id}
+			p.Gauge("fdb_role_queue_bytes", "Bytes received but not yet durable.", float64(role.QueueBytes()), labels...)
+			p.Gauge("fdb_role_input_bytes_hz", "Bytes received per second.", role.InputBytes.Hz, labels...)
+			p.Gauge("fdb_role_durable_bytes_hz", "Bytes made durable per second.", role.DurableBytes.Hz, labels...)
+			p.Gauge("fdb_role_kvstore_used_bytes", "Bytes used by the key/value store.", float64(role.KvstoreUsedBytes), labels...)
+			if role.Role == StorageRoleMetrics {
+				p.Gauge("fdb_role_data_lag_seconds", "Data lag of the storage server.", role.DataLag.Seconds, labels...)
+				p.Gauge("fdb_role_durability_lag_seconds", "Durability lag of the storage server.", role.DurabilityLag.Seconds, labels...)
+				p.Gauge("fdb_role_bytes_queried_hz", "Bytes queried per second.", role.BytesQueried.Hz, labels...)
+				p.Gauge("fdb_role_mutation_bytes_hz", "Bytes of mutations per second.", role.MutationBytes.Hz, labels...)
+				p.Gauge("fdb_role_stored_bytes", "Bytes stored by the storage server.", float64(role.StoredBytes), labels...)
+			} else {
+				p.Gauge("fdb_role_queue_disk_used_bytes", "Disk used by the queue of the log server.", float64(role.QueueDiskUsedBytes), labels...)
+			}
+		}
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// PromWriter formats metrics in the prometheus text format,
+// keeping the samples of a metric together whatever the order they are added.
+type PromWriter struct {
+	families []*promFamily
+	byName   map[string]*promFamily
+}
+
+// PromLabeller adds samples to a PromWriter with a set of common labels.
+type PromLabeller struct {
+	w      *PromWriter
+	labels []string
+}
+
+func (p *PromWriter) WithLabels(labels ...string) PromLabeller {
+	return PromLabeller{w: p, labels: labels}
+}
+
+func (p PromLabeller) Gauge(name string, help string, value float64, labels ...string) {
+	p.w.add(name, help, "gauge", value, append(append([]string{}, p.labels...), labels...))
+}
+
+func (p PromLabeller) Counter(name string, help string, value float64, labels ...string) {
+	p.w.add(name, help, "counter", value, append(append([]string{}, p.labels...), labels...))
+}
+
+type promFamily struct {
+	name    string
+	help    string
+	kind    string
+	samples []string
+}
+
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (p *PromWriter) add(name string, help string, kind string, value float64, labels []string) {
+	if p.byName == nil {
+		p.byName = make(map[string]*promFamily)
+	}
+	family, ok := p.byName[name]
+	if !ok {
+		family = &promFamily{name: name, help: help, kind: kind}
+		p.byName[name] = family
+		p.families = append(p.families, family)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(name)
+	if len(labels) > 0 {
+		sb.WriteString("{")
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			fmt.Fprintf(&sb, `%s="%s"`, labels[i], promLabelEscaper.Replace(labels[i+1]))
+		}
+		sb.WriteString("}")
+	}
+	sb.WriteString(" ")
+	sb.WriteString(formatPromValue(value))
+	family.samples = append(family.samples, sb.String())
+}
+
+func formatPromValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return fmt.Sprintf("%g", value)
+}
+
+func (p *PromWriter) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	for _, family := range p.families {
+		fmt.Fprintf(&sb, "# HELP %s %s\n", family.name, family.help)
+		fmt.Fprintf(&sb, "# TYPE %s %s\n", family.name, family.kind)
+		for _, sample := range family.samples {
+			sb.WriteString(sample)
+			sb.WriteString("\n")
+		}
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strings"
+)
+
+// expectedTolerance is the number of zone failures a healthy cluster survives, per redundancy mode.
+var expectedTolerance = map[string]int64{
+	"single":                    0,
+	"double":                    1,
+	"triple":                    2,
+	"three_data_hall":           2,
+	"three_data_hall_fallback":  1,
+	"three_datacenter":          2,
+	"three_datacenter_fallback": 1,
+}
+
+// ExpectedTolerance returns the fault tolerance expected from a redundancy mode,
+// and false for an unknown mode.
+func ExpectedTolerance(mode string) (int64, bool) {
+	tolerance, ok := expectedTolerance[mode]
+	return tolerance, ok
+}
+
+// ToleranceColor is red when the tolerance is below the one expected from the redundancy mode,
+// and yellow when the mode is unknown and no zone failure is tolerated.
+func ToleranceColor(tolerance int64, mode string) string {
+	expected, ok := ExpectedTolerance(mode)
+	switch {
+	case ok && tolerance < expected:
+		return "Red"
+	case !ok && tolerance <= 0:
+		return "Yellow"
+	default:
+		return "Green"
+	}
+}
+
+// ReducedTolerance returns true if the cluster tolerates fewer failures than its redundancy mode should.
+func ReducedTolerance(status *FdbStatus) bool {
+	mode := status.Cluster.Configuration.RedundancyMode
+	tolerance := status.Cluster.FaultTolerance
+	return ToleranceColor(tolerance.MaxZoneFailuresWithoutLosingAvailability, mode) == "Red" ||
+		ToleranceColor(tolerance.MaxZoneFailuresWithoutLosingData, mode) == "Red"
+}
+
+// ShowFaultsScreen displays the fault tolerance and replication health of the cluster,
+// and their History, so that short windows of reduced tolerance can be noticed.
+func ShowFaultsScreen(status FdbStatus) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 12
+		COL2 = COL1 + 23
+		COL3 = COL2 + 23
+		COL4 = COL3 + 15
+		COL5 = COL4 + 11
+
+		TOLERANCE_WIDTH = 5
+	)
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+	cluster := &status.Cluster
+	mode := cluster.Configuration.RedundancyMode
+	expected, known := ExpectedTolerance(mode)
+
+	// A cluster that already lost replicas reports a negative tolerance, drawn without bar
+	bar := func(tolerance int64) string {
+		if tolerance < 0 {
+			tolerance = 0
+		}
+		return strings.Repeat("|", int(tolerance)*TOLERANCE_WIDTH)
+	}
+
+	for y := 5; y <= 9; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 5, "Redundancy")
+	WriteAtS(COL0, 6, "Zone failures tolerated without losing")
+	WriteAtS(COL0+4, 7, "availability")
+	WriteAtS(COL0+4, 8, "data")
+	WriteAtS(COL3, 7, "Full replication")
+	WriteAtS(COL3, 8, "Min replicas")
+	WriteAtS(COL3, 9, "Degraded")
+
+	SetColor("White")
+	WriteAtS(COL1, 5, mode)
+	SetColor("Gray")
+	if known {
+		WriteAt(COL2, 5, "expects %d zone failure(s) tolerated", expected)
+	} else {
+		WriteAtS(COL2, 5, "unknown redundancy mode")
+	}
+
+	availability := cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability
+	data := cluster.FaultTolerance.MaxZoneFailuresWithoutLosingData
+	SetColor(ToleranceColor(availability, mode))
+	WriteAt(COL2, 7, "%d", availability)
+	SetColor(ToleranceColor(data, mode))
+	WriteAt(COL2, 8, "%d", data)
+
+	SetColorIf(cluster.FullReplication, "Green", "Red")
+	if cluster.FullReplication {
+		WriteAtS(COL3+18, 7, "yes")
+	} else {
+		WriteAtS(COL3+18, 7, "NO")
+	}
+	SetColorIf(cluster.Data.State.MinReplicasRemaining > expected, "Green", "Red")
+	WriteAt(COL3+18, 8, "%d", cluster.Data.State.MinReplicasRemaining)
+	SetColorIf(cluster.DegradedProcesses > 0, "Yellow", "Green")
+	WriteAt(COL3+18, 9, "%d process(es)", cluster.DegradedProcesses)
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 11, "Elapsed")
+	WriteAtS(COL1, 11, "Availability")
+	WriteAtS(COL2, 11, "Data")
+	WriteAtS(COL3, 11, "Full repl.")
+	WriteAtS(COL4, 11, "Replicas")
+	WriteAtS(COL5, 11, "Degraded")
+
+	y := 13 + len(History) - 1
+	for _, metric := range History {
+		if y < ScreenHeight-1 {
+			SetColor("DarkGray")
+			WriteAt(COL0, y, "%9s | %20s | %20s | %12s | %8s | %8s |", TimeSpanInSecondsWithRounding(metric.LocalTime.Seconds()), "", "", "", "", "")
+
+			if metric.Available {
+				SetColor(ToleranceColor(metric.ToleranceAvailability, mode))
+				WriteAt(COL1, y, "%2d %s", metric.ToleranceAvailability, bar(metric.ToleranceAvailability))
+				SetColor(ToleranceColor(metric.ToleranceData, mode))
+				WriteAt(COL2, y, "%2d %s", metric.ToleranceData, bar(metric.ToleranceData))
+				SetColorIf(metric.FullReplication, "DarkGreen", "Red")
+				if metric.FullReplication {
+					WriteAt(COL3, y, "%12s", "yes")
+				} else {
+					WriteAt(COL3, y, "%12s", "NO")
+				}
+				SetColorIf(metric.MinReplicasRemaining > expected, "DarkGreen", "Red")
+				WriteAt(COL4, y, "%8d", metric.MinReplicasRemaining)
+				SetColorIf(metric.DegradedProcesses > 0, "Yellow", "DarkGreen")
+				WriteAt(COL5, y, "%8d", metric.DegradedProcesses)
+			} else {
+				SetColor("DarkRed")
+				WriteAt(COL1, y, "%2s", "x")
+				WriteAt(COL2, y, "%2s", "x")
+			}
+		}
+		y--
+	}
+}
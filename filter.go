@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// filterFields are the text fields of a process a filter can match, by key.
+var filterFields = map[string]func(p *FdbProcess) string{
+	"address":  func(p *FdbProcess) string { return p.Address },
+	"machine":  func(p *FdbProcess) string { return p.MachineId },
+	"zone":     func(p *FdbProcess) string { return p.Locality.Zoneid },
+	"hall":     func(p *FdbProcess) string { return p.Locality.DataHall },
+	"dc":       func(p *FdbProcess) string { return p.Locality.Dcid },
+	"class":    func(p *FdbProcess) string { return p.ClassType },
+	"version":  func(p *FdbProcess) string { return p.Version },
+	"excluded": func(p *FdbProcess) string { return strconv.FormatBool(p.Excluded) },
+}
+
+// filterNumbers are the numeric fields of a process a filter can compare, by key.
+var filterNumbers = map[string]func(p *FdbProcess) float64{
+	"cpu":    func(p *FdbProcess) float64 { return p.Cpu.UsageCores },
+	"disk":   func(p *FdbProcess) float64 { return p.Disk.Busy },
+	"mem":    func(p *FdbProcess) float64 { return GigaBytes(p.Memory.UsedBytes - p.Memory.UnusedAllocatedMemory) },
+	"uptime": func(p *FdbProcess) float64 { return p.UptimeSeconds },
+}
+
+// filterTerm is a comparison of a field of a process with a value,
+// or a text searched in all the text fields when key is empty.
+type filterTerm struct {
+	key    string
+	op     string
+	value  string
+	number float64
+}
+
+// ProcessFilter selects the processes, and the roles, shown on the Processes and Roles screens.
+// All its terms must match.
+type ProcessFilter struct {
+	Text  string
+	terms []filterTerm
+}
+
+// processFilter is the filter of the Processes and Roles screens.
+var processFilter ProcessFilter
+
+// ParseProcessFilter parses a filter made of terms separated by spaces, like `role=storage cpu>0.8 dc=us-east`.
+// Text fields are compared ignoring case with = and != to a text they equal, and with ~ and !~ to a text
+// they contain, numeric fields with =, !=, <, <=, > and >=. A term without operator is searched in all the text fields.
+func ParseProcessFilter(text string) (ProcessFilter, error) {
+	filter := ProcessFilter{Text: strings.TrimSpace(text)}
+	for _, word := range strings.Fields(text) {
+		term, err := parseFilterTerm(word)
+		if err != nil {
+			return ProcessFilter{}, err
+		}
+		filter.terms = append(filter.terms, term)
+	}
+	return filter, nil
+}
+
+func parseFilterTerm(word string) (filterTerm, error) {
+	i := strings.IndexAny(word, "!=<>~")
+	if i < 0 {
+		return filterTerm{value: strings.ToLower(word)}, nil
+	}
+
+	op := word[i : i+1]
+	if i+1 < len(word) && (word[i+1] == '=' && op != "=" && op != "~" || word[i+1] == '~' && op == "!") {
+		op += word[i+1 : i+2]
+	}
+	term := filterTerm{key: strings.ToLower(word[:i]), op: op, value: strings.ToLower(word[i+len(op):])}
+	if op == "!" {
+		return term, errors.Errorf("%s: invalid operator, expected =, !=, ~, !~, <, <=, > or >=", word)
+	}
+
+	if _, ok := filterNumbers[term.key]; ok {
+		if op == "~" || op == "!~" {
+			return term, errors.Errorf("%s: %s only compares with =, !=, <, <=, > and >=", word, term.key)
+		}
+		number, err := strconv.ParseFloat(term.value, 64)
+		if err != nil {
+			return term, errors.Errorf("%s: %s is not a number", word, term.value)
+		}
+		term.number = number
+		return term, nil
+	}
+	if _, ok := filterFields[term.key]; !ok && term.key != "role" {
+		var keys []string
+		for key := range filterFields {
+			keys = append(keys, key)
+		}
+		for key := range filterNumbers {
+			keys = append(keys, key)
+		}
+		keys = append(keys, "role")
+		sort.Strings(keys)
+		return term, errors.Errorf("%s: unknown field %q, expected one of %s", word, term.key, strings.Join(keys, ", "))
+	}
+	if op != "=" && op != "!=" && op != "~" && op != "!~" {
+		return term, errors.Errorf("%s: %s only compares with =, !=, ~ and !~", word, term.key)
+	}
+	return term, nil
+}
+
+// Matches returns true if the process matches the filter. When role is not nil,
+// role terms are compared to this role of the process rather than to any of them.
+func (f *ProcessFilter) Matches(p *FdbProcess, role *FdbRole) bool {
+	for i := range f.terms {
+		if !f.terms[i].matches(p, role) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *filterTerm) matches(p *FdbProcess, role *FdbRole) bool {
+	var roles []string
+	if role != nil {
+		roles = []string{role.Role}
+	} else {
+		for _, r := range p.Roles {
+			roles = append(roles, r.Role)
+		}
+	}
+	contains := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), t.value)
+	}
+	compare := func(s string) bool {
+		if t.op == "~" || t.op == "!~" {
+			return contains(s)
+		}
+		return strings.ToLower(s) == t.value
+	}
+
+	switch {
+	case t.key == "":
+		for key, field := range filterFields {
+			if key != "excluded" && contains(field(p)) {
+				return true
+			}
+		}
+		for _, r := range roles {
+			if contains(r) {
+				return true
+			}
+		}
+		return false
+	case t.key == "role":
+		found := false
+		for _, r := range roles {
+			found = found || compare(r)
+		}
+		return found == (t.op == "=" || t.op == "~")
+	case filterFields[t.key] != nil:
+		return compare(filterFields[t.key](p)) == (t.op == "=" || t.op == "~")
+	}
+
+	x := filterNumbers[t.key](p)
+	switch t.op {
+	case "=":
+		return x == t.number
+	case "!=":
+		return x != t.number
+	case "<":
+		return x < t.number
+	case "<=":
+		return x <= t.number
+	case ">":
+		return x > t.number
+	default:
+		return x >= t.number
+	}
+}
+
+// ShowProcessFilter displays the filter of the Processes and Roles screens on the right of the line
+// above their headers, with the number of rows it selects.
+func ShowProcessFilter(shown, total int, what string) {
+	if processFilter.Text == "" {
+		return
+	}
+	// Between the sort label and the scroll position
+	const COL_SORT = 1 + 18 + 2
+	ScreenWidth, _ := screen.Size()
+	text := fmt.Sprintf("%d of %d %s matching %q", shown, total, what, processFilter.Text)
+	text = trimMax(text, ScreenWidth-26-COL_SORT)
+	SetColor("Yellow")
+	WriteAtS(ScreenWidth-26-utf8.RuneCountInString(text), 4, text)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProcessFilter(t *testing.T) {
+	for _, tt := range []struct {
+		text  string
+		terms []filterTerm
+	}{
+		{"", nil},
+		{"storage", []filterTerm{{value: "storage"}}},
+		{" role=Storage  cpu>0.8 ", []filterTerm{{key: "role", op: "=", value: "storage"}, {key: "cpu", op: ">", value: "0.8", number: 0.8}}},
+		{"dc!=us-east", []filterTerm{{key: "dc", op: "!=", value: "us-east"}}},
+		{"dc~us", []filterTerm{{key: "dc", op: "~", value: "us"}}},
+		{"dc!~us", []filterTerm{{key: "dc", op: "!~", value: "us"}}},
+		{"uptime<=60 mem>=2", []filterTerm{{key: "uptime", op: "<=", value: "60", number: 60}, {key: "mem", op: ">=", value: "2", number: 2}}},
+	} {
+		filter, err := ParseProcessFilter(tt.text)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.text, err)
+			continue
+		}
+		if filter.Text != strings.TrimSpace(tt.text) {
+			t.Errorf("%q: got text %q", tt.text, filter.Text)
+		}
+		if len(filter.terms) != len(tt.terms) {
+			t.Errorf("%q: got terms %+v, expected %+v", tt.text, filter.terms, tt.terms)
+			continue
+		}
+		for i := range tt.terms {
+			if filter.terms[i] != tt.terms[i] {
+				t.Errorf("%q: got term %+v, expected %+v", tt.text, filter.terms[i], tt.terms[i])
+			}
+		}
+	}
+}
+
+func TestParseProcessFilterErrors(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		err  string
+	}{
+		{"host=a", `unknown field "host"`},
+		{"cpu>high", "high is not a number"},
+		{"cpu~1", "cpu only compares with =, !=, <, <=, > and >="},
+		{"dc<us", "dc only compares with =, !=, ~ and !~"},
+		{"role!storage", "invalid operator"},
+		{"storage dc>=us", "dc only compares with"},
+	} {
+		_, err := ParseProcessFilter(tt.text)
+		if err == nil {
+			t.Errorf("%q: expected an error", tt.text)
+		} else if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%q: got error %q, expected %q", tt.text, err, tt.err)
+		}
+	}
+}
+
+func TestProcessFilterMatches(t *testing.T) {
+	proc := FdbProcess{Address: "10.0.0.1:45001", Version: "7.10.2"}
+	proc.Locality.Dcid = "us-east"
+	proc.Roles = []FdbRole{{Role: "storage"}, {Role: "log"}}
+
+	for _, tt := range []struct {
+		text    string
+		matches bool
+	}{
+		{"address=10.0.0.1:4500", false},
+		{"address=10.0.0.1:45001", true},
+		{"address~10.0.0.1:4500", true},
+		{"dc=us", false},
+		{"dc=US-East", true},
+		{"dc!=us", true},
+		{"dc!~us", false},
+		{"version=7.1", false},
+		{"role=log", true},
+		{"role=stor", false},
+		{"role!=log", false},
+		{"east", true},
+		{"west", false},
+		{"excluded=false dc=us-east", true},
+	} {
+		filter, err := ParseProcessFilter(tt.text)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", tt.text, err)
+		}
+		if filter.Matches(&proc, nil) != tt.matches {
+			t.Errorf("%q: expected matches %t", tt.text, tt.matches)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type HealthLevel int
+
+const (
+	Healthy HealthLevel = iota
+	Degraded
+	Critical
+)
+
+// Health is the state of the database, as shown by the indicator of the top bar.
+type Health struct {
+	Level   HealthLevel
+	Reasons []string
+}
+
+// CheckHealth returns the state of the database seen by the cluster and by fdbtop as a client,
+// with the reasons of the state, most severe first.
+func CheckHealth(status *FdbStatus) Health {
+	var health Health
+	add := func(level HealthLevel, reason string) {
+		if level > health.Level {
+			health.Level = level
+		}
+		health.Reasons = append(health.Reasons, reason)
+	}
+
+	client := &status.Client
+	if !status.Cluster.DatabaseAvailable || !client.DatabaseStatus.Available {
+		add(Critical, "unavailable")
+	}
+	if !client.Coordinators.QuorumReachable {
+		add(Critical, "coordinators unreachable")
+	}
+	if !client.DatabaseStatus.Healthy {
+		add(Degraded, "unhealthy")
+	}
+	if !client.ClusterFile.UpToDate {
+		add(Degraded, "cluster file out of date")
+	}
+	return health
+}
+
+// Label is the text of the indicator, the first reason and the count of the others.
+func (h Health) Label() string {
+	switch len(h.Reasons) {
+	case 0:
+		return "Available"
+	case 1:
+		return strings.ToUpper(h.Reasons[0])
+	default:
+		return fmt.Sprintf("%s (+%d)", strings.ToUpper(h.Reasons[0]), len(h.Reasons)-1)
+	}
+}
+
+func (h Health) Color() string {
+	switch h.Level {
+	case Critical:
+		return "Red"
+	case Degraded:
+		return "Yellow"
+	default:
+		return "Green"
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// maxRecoveries is the number of recovery events kept per cluster.
+const maxRecoveries = 100
+
+// RecoveryEvent is a new generation, or a change of the recovery state, seen during the session.
+type RecoveryEvent struct {
+	When        time.Time
+	Generation  int64
+	State       string
+	Description string
+}
+
+// AppendRecovery adds a recovery event to recoveries if the status of ev recovered
+// or changed of recovery state since the previous status. A status without recovery state or generation,
+// as returned partially, is not compared.
+func AppendRecovery(recoveries []RecoveryEvent, previous *FdbStatus, ev *StatusEvent) []RecoveryEvent {
+	before := &previous.Cluster
+	after := &ev.status.Cluster
+	if before.RecoveryState.Name == "" || after.RecoveryState.Name == "" || before.Generation == 0 || after.Generation == 0 {
+		return recoveries
+	}
+	if after.Generation == before.Generation &&
+		after.RecoveryState.Name == before.RecoveryState.Name &&
+		after.RecoveryState.SecondsSinceLastRecovered >= before.RecoveryState.SecondsSinceLastRecovered {
+		return recoveries
+	}
+
+	recoveries = append(recoveries, RecoveryEvent{
+		When:        ev.when,
+		Generation:  after.Generation,
+		State:       after.RecoveryState.Name,
+		Description: after.RecoveryState.Description,
+	})
+	if len(recoveries) > maxRecoveries {
+		recoveries = recoveries[len(recoveries)-maxRecoveries:]
+	}
+	return recoveries
+}
+
+// ShowLogsScreen displays the recovery state, the generations of transaction logs,
+// and the recoveries seen during the session.
+func ShowLogsScreen(status FdbStatus, recoveries []RecoveryEvent) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 12
+		COL2 = COL1 + 10
+		COL3 = COL2 + 16
+		COL4 = COL3 + 14
+		COL5 = COL4 + 14
+		COL6 = COL5 + 18
+		COL7 = COL6 + 14
+	)
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+	recovery := &status.Cluster.RecoveryState
+
+	for y := 5; y <= 7; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 5, "Recovery")
+	WriteAtS(COL0, 6, "Recovered")
+	WriteAtS(COL0, 7, "Generation")
+
+	SetColorIf(recovery.Name == "fully_recovered", "Green", "Yellow")
+	WriteAtS(COL1, 5, recovery.Name)
+	SetColor("Gray")
+	WriteAtS(COL1+len(recovery.Name)+2, 5, trimMax(recovery.Description, ScreenWidth-COL1-len(recovery.Name)-3))
+
+	since := time.Duration(recovery.SecondsSinceLastRecovered * float64(time.Second))
+	SetColorIf(since < 5*time.Minute, "Yellow", "Gray")
+	WriteAt(COL1, 6, "%s ago", since.Round(time.Second))
+	SetColor("Gray")
+	WriteAt(COL1, 7, "%d, %d active", status.Cluster.Generation, recovery.ActiveGenerations)
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 9, "Epoch")
+	WriteAtS(COL1, 9, "Current")
+	WriteAtS(COL2, 9, "   Begin version")
+	WriteAtS(COL3, 9, " Replication")
+	WriteAtS(COL4, 9, " Anti-quorum")
+	WriteAtS(COL5, 9, " Fault tolerance")
+	WriteAtS(COL6, 9, "  Interfaces")
+	WriteAtS(COL7, 9, "Unhealthy")
+
+	y := 10
+	for _, log := range status.Cluster.Logs {
+		if y >= ScreenHeight-1 {
+			break
+		}
+		WriteAtS(0, y, emptyLine)
+
+		healthy := 0
+		var unhealthy []string
+		for _, i := range log.LogInterfaces {
+			if i.Healthy {
+				healthy++
+			} else {
+				unhealthy = append(unhealthy, i.Address)
+			}
+		}
+
+		SetColorIf(log.Current, "White", "Gray")
+		WriteAt(COL0, y, "%5d", log.Epoch)
+		if log.Current {
+			WriteAtS(COL1, y, "current")
+		} else {
+			WriteAtS(COL1, y, "old")
+		}
+		WriteAt(COL2, y, "%16d", log.BeginVersion)
+		WriteAt(COL3, y, "%12d", log.LogReplicationFactor)
+		WriteAt(COL4, y, "%12d", log.LogWriteAntiQuorum)
+		SetColorIf(log.LogFaultTolerance > 0, "Gray", "Red")
+		WriteAt(COL5, y, "%16d", log.LogFaultTolerance)
+		SetColorIf(len(unhealthy) > 0, "Yellow", "Gray")
+		WriteAt(COL6, y, "%6d / %3d", healthy, len(log.LogInterfaces))
+		SetColor("Red")
+		if log.PossiblyLosingData {
+			unhealthy = append([]string{"POSSIBLY LOSING DATA"}, unhealthy...)
+		}
+		WriteAtS(COL7, y, trimMax(strings.Join(unhealthy, " "), ScreenWidth-COL7-1))
+		y++
+	}
+
+	// Old generations drop out after a recovery, moving up the lines below them
+	if y < ScreenHeight-1 {
+		WriteAtS(0, y, emptyLine)
+	}
+	y++
+	if y >= ScreenHeight-1 {
+		return
+	}
+	WriteAtS(0, y, emptyLine)
+	SetColor("DarkCyan")
+	WriteAtS(COL0, y, "Recoveries seen during the session")
+	y++
+	if len(recoveries) == 0 && y < ScreenHeight-1 {
+		WriteAtS(0, y, emptyLine)
+		SetColor("DarkGray")
+		WriteAtS(COL0, y, "none")
+		y++
+	}
+	for i := len(recoveries) - 1; i >= 0 && y < ScreenHeight-1; i-- {
+		r := &recoveries[i]
+		WriteAtS(0, y, emptyLine)
+		SetColor("Gray")
+		WriteAtS(COL0, y, r.When.UTC().Format("2006-01-02 15:04:05"))
+		WriteAt(COL0+21, y, "generation %-6d", r.Generation)
+		SetColorIf(r.State == "fully_recovered", "Green", "Yellow")
+		WriteAtS(COL0+40, y, r.State)
+		SetColor("DarkGray")
+		WriteAtS(COL0+40+len(r.State)+2, y, trimMax(r.Description, ScreenWidth-COL0-len(r.State)-43))
+		y++
+	}
+	for ; y < ScreenHeight-1; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+}
@@ -4,10 +4,9 @@ import (
 	"fmt"
 	"github.com/gdamore/tcell/v2"
 	"log"
+	"os"
 	"sync"
 	"time"
-
-	"github.com/apple/foundationdb/bindings/go/src/fdb"
 )
 
 type DisplayMode int
@@ -18,11 +17,25 @@ const (
 	Processes
 	Roles
 	Transactions
+	Overview
+	Qos
+	Data
+	Logs
+	Coordinators
+	Clients
+	Backup
+	Messages
+	Faults
+	Config
+	ReadLatency
 )
 
 type StatusEvent struct {
-	when   time.Time
-	status FdbStatus
+	when    time.Time
+	cluster *Cluster
+	status  FdbStatus
+	err     error
+	warning error
 }
 
 func (s *StatusEvent) When() time.Time {
@@ -32,14 +45,91 @@ func (s *StatusEvent) When() time.Time {
 var screen tcell.Screen
 
 func main() {
-	// Different API versions may expose different runtime behaviors.
-	if err := fdb.APIVersion(710); err != nil {
-		log.Fatal("fdb init failed", err)
+	if err := ParseOptions(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
-	db, err := fdb.OpenDefault()
-	if err != nil {
-		log.Fatal("fdb init failed", err)
+	var (
+		records []SessionRecord
+		err     error
+	)
+	if *replay != "" {
+		records, err = ReadSession(*replay)
+		if err != nil && len(records) == 0 {
+			log.Fatal(err)
+		}
+		if len(records) == 0 {
+			log.Fatalf("%s: empty session", *replay)
+		}
+		name, _ := ClusterName(*replay)
+		Clusters = []*Cluster{NewCluster(name, nil)}
+		if err != nil {
+			// A damaged session is replayed up to the damage
+			Clusters[0].Warning = fmt.Errorf("%v, replaying the %d records before it", err, len(records))
+			if batch || *exportPath != "" {
+				log.Println(Clusters[0].Warning)
+			}
+		}
+	} else {
+		Clusters, err = openClusters()
+		if err != nil {
+			log.Fatal("cannot open status source: ", err)
+		}
+	}
+	ActiveCluster = Clusters[0]
+
+	if *record != "" {
+		if len(Clusters) > 1 {
+			log.Fatal("cannot record several clusters in one session")
+		}
+		writer, err := OpenSessionWriter(*record)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer writer.Close()
+		ActiveCluster.Source = NewRecordingSource(ActiveCluster.Source, writer)
+	}
+
+	observe := func(*StatusEvent) {}
+	if *promListen != "" {
+		exporter := NewExporter()
+		if err := exporter.Serve(*promListen); err != nil {
+			log.Fatal(err)
+		}
+		observe = exporter.Update
+	}
+
+	pollInterval := *interval
+	if records != nil && (batch || *exportPath != "") {
+		ActiveCluster.Source = NewSessionSource(records)
+		pollInterval = 0
+	}
+
+	if *exportPath != "" {
+		if len(Clusters) > 1 {
+			log.Fatal("cannot export several clusters in one file")
+		}
+		comma, err := ExportComma(*exportFmt, *exportPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		file, err := os.Create(*exportPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		if err := RunExport(file, comma, ActiveCluster.Source, pollInterval, *iterations); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if batch {
+		if err := RunBatch(os.Stdout, Clusters, pollInterval, *iterations, observe); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
 	// Initialize screen
@@ -68,12 +158,10 @@ func main() {
 	defer quit()
 
 	var (
-		lap        = time.Now()
-		mode       = Metrics
+		mode       = initialMode
 		repaint    = true
-		status     FdbStatus
 		fast       = true
-		speed      = time.Second
+		speed      = *interval
 		speedMutex sync.Mutex
 	)
 
@@ -88,22 +176,27 @@ func main() {
 		return speed
 	}
 
-	go func() {
-		for {
-			select {
-			case <-time.After(getSpeed()):
-				status, err := getMetrics(db)
-				if err != nil {
-					log.Printf("get metrics: %v\n", err)
-				}
+	var player *Player
+	if records != nil {
+		player = NewPlayer(ActiveCluster, records, screen.PostEvent)
+		ActiveCluster.Reset(records[0].When)
+		go player.Run()
+	} else {
+		for _, cluster := range Clusters {
+			cluster.Reset(time.Now())
+			go cluster.Poll(getSpeed, func(ev *StatusEvent) bool {
+				screen.PostEventWait(ev)
+				return true
+			})
+		}
 
-				err = screen.PostEvent(&StatusEvent{when: time.Now(), status: status})
-				if err != nil {
-					log.Printf("post event: %v\n", err)
-				}
+		// Refresh the screen even when no status comes, so that it shows as stale
+		go func() {
+			for range time.Tick(time.Second) {
+				screen.PostEvent(tcell.NewEventInterrupt(nil))
 			}
-		}
-	}()
+		}()
+	}
 
 	// Event loop
 	for {
@@ -112,12 +205,24 @@ func main() {
 			repaint = false
 		}
 
+		History = ActiveCluster.History
+		status := ActiveCluster.Status
+		now := time.Now()
+		if player != nil {
+			now = player.Current().When
+		}
+
 		RepaintTopBar()
-		if len(History) > 0 {
-			UpdateTopBar(status, History[len(History)-1])
+		if !ActiveCluster.Updated.IsZero() {
+			UpdateTopBar(status, ActiveCluster.Metric, ActiveCluster.Stale(now))
 		}
+		ShowStatusLine(ActiveCluster, now)
 
 		RepaintBottomBar(mode)
+		if !ShowNotice() && player != nil {
+			ShowReplayBar(player)
+		}
+		ShowPrompt()
 
 		switch mode {
 		case Metrics:
@@ -127,9 +232,39 @@ func main() {
 		case Latency:
 			ShowLatencyScreen()
 		case Processes:
-			ShowProcessesScreen(status)
+			if detailAddress != "" {
+				ShowProcessDetail(status, ActiveCluster.ProcessHistory, ActiveCluster.RoleHistory)
+			} else {
+				ShowProcessesScreen(status, ActiveCluster.ProcessHistory)
+			}
 		case Roles:
-			ShowRolesScreen(status)
+			if detailAddress != "" {
+				ShowProcessDetail(status, ActiveCluster.ProcessHistory, ActiveCluster.RoleHistory)
+			} else {
+				ShowRolesScreen(status, ActiveCluster.ProcessHistory, ActiveCluster.RoleHistory)
+			}
+		case Overview:
+			ShowOverviewScreen(now)
+		case Qos:
+			ShowQosScreen(status)
+		case Data:
+			ShowDataScreen(status)
+		case Logs:
+			ShowLogsScreen(status, ActiveCluster.Recoveries)
+		case Coordinators:
+			ShowCoordinatorsScreen(status)
+		case Clients:
+			ShowClientsScreen(status)
+		case Backup:
+			ShowBackupScreen(status)
+		case Messages:
+			ShowMessagesScreen(status, ActiveCluster.NewMessages)
+		case Faults:
+			ShowFaultsScreen(status)
+		case Config:
+			ShowConfigScreen(status, ActiveCluster.ConfigChanges)
+		case ReadLatency:
+			ShowReadLatencyScreen(status)
 		}
 
 		// Update screen
@@ -141,66 +276,124 @@ func main() {
 		// Process event
 		switch ev := ev.(type) {
 		case *StatusEvent:
-			status = ev.status
-			metric := HistoryMetric{
-				Available:             status.ReadVersion > 0,
-				LocalTime:             time.Now().Sub(lap),
-				Timestamp:             status.Cluster.ClusterControllerTimestamp,
-				ReadVersion:           status.ReadVersion,
-				ReadsPerSecond:        status.Cluster.Workload.Operations.Reads.Hz,
-				WritesPerSecond:       status.Cluster.Workload.Operations.Writes.Hz,
-				WrittenBytesPerSecond: status.Cluster.Workload.Bytes.Written.Hz,
-				TransStarted:          status.Cluster.Workload.Transactions.Started.Hz,
-				TransCommitted:        status.Cluster.Workload.Transactions.Committed.Hz,
-				TransConflicted:       status.Cluster.Workload.Transactions.Conflicted.Hz,
-				LatencyCommit:         status.Cluster.LatencyProbe.CommitSeconds,
-				LatencyRead:           status.Cluster.LatencyProbe.ReadSeconds,
-				LatencyStart:          status.Cluster.LatencyProbe.TransactionStartSeconds,
+			ev.cluster.Update(ev)
+			observe(ev)
+			if changes := ev.cluster.ConfigChanges; len(changes) > 0 && changes[len(changes)-1].When == ev.when {
+				c := changes[len(changes)-1]
+				Notify(fmt.Sprintf("%s: configuration changed, %s %s -> %s", ev.cluster.Name, c.Name, c.Old, c.New))
 			}
-			History = append(History, metric)
-			if len(History) > maxHistory {
-				History = History[1:]
+		case *ReplayEvent:
+			repaint = true
+			ev.cluster.Rewind()
+			for _, e := range ev.events {
+				ev.cluster.Update(e)
+			}
+			// The exported metrics follow the position of the replay
+			if n := len(ev.events); n > 0 {
+				observe(ev.events[n-1])
 			}
-
 		case *tcell.EventResize:
 			screen.Sync()
+		case *tcell.EventMouse:
+			if ev.Buttons()&tcell.Button1 != 0 && IsHealthIndicator(ev.Position()) && mode != Messages {
+				mode = Messages
+				repaint = true
+			} else if scroll, ok := ScreenScroll(mode); ok && scroll.HandleMouse(ev) {
+				repaint = true
+			} else if scroll, ok := Scrolls[mode]; ok && detailAddress == "" && ev.Buttons()&tcell.Button1 != 0 {
+				_, y := ev.Position()
+				if key, ok := scroll.ItemAt(y); ok && key != scroll.Cursor {
+					scroll.Cursor = key
+					repaint = true
+				}
+			}
 		case *tcell.EventKey:
+			if HandlePromptKey(ev) {
+				continue
+			}
+			if player != nil && HandleReplayKey(player, ev) {
+				continue
+			}
+			if (mode == Processes || mode == Roles) && HandleDetailKey(ev, mode) {
+				repaint = true
+				continue
+			}
+			if mode == Processes && detailAddress == "" && HandleProcessesKey(ev) {
+				repaint = true
+				continue
+			}
+			if scroll, ok := ScreenScroll(mode); ok && scroll.HandleKey(ev) {
+				repaint = true
+				continue
+			}
+
 			if ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyCtrlC || ev.Rune() == 'q' {
 				return
-			} else if ev.Rune() == 'c' {
-				repaint = true
-				lap = time.Now()
-				History = nil
-			} else if ev.Rune() == 'f' {
-				fast = !fast
-				if fast {
-					setSpeed(time.Second)
+			} else if ev.Key() == tcell.KeyTab || ev.Key() == tcell.KeyBacktab {
+				i := 0
+				for Clusters[i] != ActiveCluster {
+					i++
+				}
+				if ev.Key() == tcell.KeyTab {
+					i++
 				} else {
-					setSpeed(time.Second * 2)
+					i += len(Clusters) - 1
 				}
-			} else if ev.Rune() == 'p' {
-				if mode != Processes {
-					mode = Processes
+				if next := Clusters[i%len(Clusters)]; next != ActiveCluster {
+					ActiveCluster = next
+					detailAddress = ""
 					repaint = true
 				}
-			} else if ev.Rune() == 'm' {
-				if mode != Metrics {
-					mode = Metrics
+			} else if ev.Rune() >= '1' && ev.Rune() <= '9' {
+				if i := int(ev.Rune() - '1'); i < len(Clusters) && Clusters[i] != ActiveCluster {
+					ActiveCluster = Clusters[i]
+					detailAddress = ""
 					repaint = true
 				}
-			} else if ev.Rune() == 'l' {
-				if mode != Latency {
-					mode = Latency
-					repaint = true
+			} else if ev.Rune() == 'c' {
+				repaint = true
+				if player != nil {
+					ActiveCluster.Reset(player.Current().When)
+				} else {
+					ActiveCluster.Reset(time.Now())
 				}
-			} else if ev.Rune() == 'r' {
-				if mode != Roles {
-					mode = Roles
+			} else if ev.Rune() == 'e' {
+				if len(History) == 0 {
+					Notify("Nothing to export yet")
+				} else if path, err := ExportHistory(*exportFmt); err != nil {
+					Notify(err.Error())
+				} else {
+					Notify(fmt.Sprintf("Exported %d rows to %s", len(History), path))
+				}
+			} else if ev.Rune() == '!' && mode != Messages {
+				mode = Messages
+				repaint = true
+			} else if ev.Rune() == '/' && mode == Messages {
+				OpenPrompt("Filter messages:", messageFilter, func(text string) {
+					messageFilter = text
+				})
+			} else if ev.Rune() == '/' && (mode == Processes || mode == Roles) {
+				OpenPrompt("Filter processes (e.g. role=storage cpu>0.8 dc=us-east):", processFilter.Text, func(text string) {
+					filter, err := ParseProcessFilter(text)
+					if err != nil {
+						Notify(err.Error())
+						return
+					}
+					processFilter = filter
+					Scrolls[Processes].Offset = 0
+					Scrolls[Roles].Offset = 0
 					repaint = true
+				})
+			} else if ev.Rune() == 'f' {
+				fast = !fast
+				if fast {
+					setSpeed(*interval)
+				} else {
+					setSpeed(*interval * 2)
 				}
-			} else if ev.Rune() == 't' {
-				if mode != Transactions {
-					mode = Transactions
+			} else if tab, ok := ScreenTabForKey(ev.Rune()); ok {
+				if mode != tab.Mode {
+					mode = tab.Mode
 					repaint = true
 				}
 			}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+type MessageSeverity int
+
+const (
+	SeverityInfo MessageSeverity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s MessageSeverity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+func (s MessageSeverity) Color() string {
+	switch s {
+	case SeverityError:
+		return "Red"
+	case SeverityWarning:
+		return "Yellow"
+	default:
+		return "Gray"
+	}
+}
+
+// errorMessages are the cluster and client messages that mean the database is in trouble,
+// other messages are warnings.
+var errorMessages = map[string]bool{
+	"unreachable_master_worker":             true,
+	"unreachable_cluster_controller_worker": true,
+	"unreachable_dataDistributor_worker":    true,
+	"unreachable_ratekeeper_worker":         true,
+	"unreadable_configuration":              true,
+	"full_replication_timeout":              true,
+	"storage_servers_error":                 true,
+	"log_servers_error":                     true,
+	"transaction_start_timeout":             true,
+	"read_timeout":                          true,
+	"commit_timeout":                        true,
+	"quorum_not_reachable":                  true,
+	"status_incomplete_coordinators":        true,
+	"no_cluster_controller":                 true,
+}
+
+// StatusMessage is a message with the part of the cluster it comes from.
+type StatusMessage struct {
+	Source string
+	FdbMessage
+}
+
+// Severity returns the severity of the message, from its trace severity if any.
+// Process messages report errors of the process.
+func (m *StatusMessage) Severity() MessageSeverity {
+	switch {
+	case m.FdbMessage.Severity >= 40:
+		return SeverityError
+	case m.FdbMessage.Severity >= 20:
+		return SeverityWarning
+	case m.FdbMessage.Severity > 0:
+		return SeverityInfo
+	case errorMessages[m.Name] || m.Type != "":
+		return SeverityError
+	default:
+		return SeverityWarning
+	}
+}
+
+// Key identifies a message from one poll to the next.
+func (m *StatusMessage) Key() string {
+	return m.Source + "\x00" + m.Name + "\x00" + m.Description
+}
+
+// Matches returns true if the filter is found in the source, severity, name or description of the message.
+func (m *StatusMessage) Matches(filter string) bool {
+	filter = strings.ToLower(filter)
+	for _, s := range []string{m.Source, m.Severity().String(), m.Name, m.Description} {
+		if strings.Contains(strings.ToLower(s), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectMessages returns the messages of the cluster, of the client and of every process,
+// most severe first.
+func CollectMessages(status *FdbStatus) []StatusMessage {
+	var messages []StatusMessage
+	for _, m := range status.Cluster.Messages {
+		messages = append(messages, StatusMessage{"cluster", m})
+	}
+	for _, m := range status.Client.Messages {
+		messages = append(messages, StatusMessage{"client", m})
+	}
+	for _, p := range status.Cluster.Processes {
+		for _, m := range p.Messages {
+			messages = append(messages, StatusMessage{p.Address, m})
+		}
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		a, b := &messages[i], &messages[j]
+		if a.Severity() != b.Severity() {
+			return a.Severity() > b.Severity()
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Name < b.Name
+	})
+	return messages
+}
+
+// NewMessages returns the keys of the messages of status that were not in previous.
+func NewMessages(previous, status *FdbStatus) map[string]bool {
+	seen := make(map[string]bool)
+	for _, m := range CollectMessages(previous) {
+		seen[m.Key()] = true
+	}
+	added := make(map[string]bool)
+	for _, m := range CollectMessages(status) {
+		if !seen[m.Key()] {
+			added[m.Key()] = true
+		}
+	}
+	return added
+}
+
+// messageFilter is the filter of the Messages screen.
+var messageFilter string
+
+// ShowMessagesScreen displays the state of the database and its messages matching messageFilter,
+// the messages that appeared with the last poll being highlighted.
+func ShowMessagesScreen(status FdbStatus, added map[string]bool) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 3
+		COL2 = COL1 + 24
+		COL3 = COL2 + 9
+		COL4 = COL3 + 10
+		COL5 = COL4 + 34
+	)
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+
+	health := CheckHealth(&status)
+	WriteAtS(0, 5, emptyLine)
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 5, "Database")
+	SetColor(health.Color())
+	WriteAtS(COL0+10, 5, health.Label())
+	if len(health.Reasons) > 1 {
+		SetColor("Yellow")
+		WriteAtS(COL0+12+len(health.Label()), 5, strings.Join(health.Reasons, ", "))
+	}
+
+	messages := CollectMessages(&status)
+	var shown []StatusMessage
+	for _, m := range messages {
+		if messageFilter == "" || m.Matches(messageFilter) {
+			shown = append(shown, m)
+		}
+	}
+
+	WriteAtS(0, 6, emptyLine)
+	SetColor("DarkGray")
+	if messageFilter != "" {
+		WriteAt(COL0, 6, "%d of %d messages matching %q, [/] to change the filter", len(shown), len(messages), messageFilter)
+	} else {
+		WriteAt(COL0, 6, "%d messages, [/] to filter", len(messages))
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL1, 8, "Source")
+	WriteAtS(COL2, 8, "Severity")
+	WriteAtS(COL3, 8, "Time")
+	WriteAtS(COL4, 8, "Name")
+	WriteAtS(COL5, 8, "Description")
+
+	y := 9
+	for _, m := range shown {
+		if y >= ScreenHeight-1 {
+			break
+		}
+		WriteAtS(0, y, emptyLine)
+
+		isNew := added[m.Key()]
+		if isNew {
+			SetColor("Cyan")
+			WriteAtS(COL0, y, "*")
+		}
+		SetColorIf(isNew, "White", "Gray")
+		WriteAtS(COL1, y, trimMax(m.Source, 23))
+		SetColor(m.Severity().Color())
+		WriteAtS(COL2, y, m.Severity().String())
+		SetColorIf(isNew, "White", "Gray")
+		if m.Time > 0 {
+			WriteAtS(COL3, y, time.Unix(int64(m.Time), 0).UTC().Format("15:04:05"))
+		} else {
+			WriteAtS(COL3, y, "-")
+		}
+		WriteAtS(COL4, y, trimMax(m.Name, 33))
+		WriteAtS(COL5, y, trimMax(m.Description, ScreenWidth-COL5-1))
+		y++
+	}
+	for ; y < ScreenHeight-1; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+}
@@ -9,27 +9,86 @@ import (
 const (
 	MAX_RW_WIDTH = 40
 	MAX_WS_WIDTH = 20
-	maxHistory   = 100
 )
 
+// maxHistory is the number of metrics kept in History, set with -max-history.
+var maxHistory = 100
+
 type HistoryMetric struct {
-	Available             bool
-	LocalTime             time.Duration
-	ReadVersion           int64
-	Timestamp             int64
-	ReadsPerSecond        float64
-	WritesPerSecond       float64
-	WrittenBytesPerSecond float64
-	TransStarted          float64
-	TransCommitted        float64
-	TransConflicted       float64
-	LatencyCommit         float64
-	LatencyRead           float64
-	LatencyStart          float64
+	Available             bool          `json:"available"`
+	LocalTime             time.Duration `json:"-"`
+	ReadVersion           int64         `json:"read_version"`
+	Timestamp             int64         `json:"timestamp"`
+	ReadsPerSecond        float64       `json:"reads_per_second"`
+	WritesPerSecond       float64       `json:"writes_per_second"`
+	WrittenBytesPerSecond float64       `json:"written_bytes_per_second"`
+	TransStarted          float64       `json:"transactions_started"`
+	TransCommitted        float64       `json:"transactions_committed"`
+	TransConflicted       float64       `json:"transactions_conflicted"`
+	LatencyCommit         float64       `json:"latency_commit"`
+	LatencyRead           float64       `json:"latency_read"`
+	LatencyStart          float64       `json:"latency_start"`
+	TpsLimit              float64       `json:"tps_limit"`
+	ReleasedTps           float64       `json:"released_tps"`
+	BatchTpsLimit         float64       `json:"batch_tps_limit"`
+	BatchReleasedTps      float64       `json:"batch_released_tps"`
+	MovingInFlightBytes   int64         `json:"moving_in_flight_bytes"`
+	MovingInQueueBytes    int64         `json:"moving_in_queue_bytes"`
+	ToleranceAvailability int64         `json:"fault_tolerance_availability"`
+	ToleranceData         int64         `json:"fault_tolerance_data"`
+	MinReplicasRemaining  int64         `json:"min_replicas_remaining"`
+	FullReplication       bool          `json:"full_replication"`
+	DegradedProcesses     int64         `json:"degraded_processes"`
+	ReadP99Median         float64       `json:"storage_read_p99_median"`
+	ReadP99Worst          float64       `json:"storage_read_p99_worst"`
 }
 
+// History is the history of the cluster on screen.
 var History []HistoryMetric
 
+// NewHistoryMetric extracts the cluster-wide metrics of a status,
+// elapsed being the time since the beginning of the session.
+func NewHistoryMetric(status FdbStatus, elapsed time.Duration) HistoryMetric {
+	readMedian, readWorst := ReadP99(StorageReadLatencies(&status))
+	return HistoryMetric{
+		Available:             status.ReadVersion > 0,
+		LocalTime:             elapsed,
+		Timestamp:             status.Cluster.ClusterControllerTimestamp,
+		ReadVersion:           status.ReadVersion,
+		ReadsPerSecond:        status.Cluster.Workload.Operations.Reads.Hz,
+		WritesPerSecond:       status.Cluster.Workload.Operations.Writes.Hz,
+		WrittenBytesPerSecond: status.Cluster.Workload.Bytes.Written.Hz,
+		TransStarted:          status.Cluster.Workload.Transactions.Started.Hz,
+		TransCommitted:        status.Cluster.Workload.Transactions.Committed.Hz,
+		TransConflicted:       status.Cluster.Workload.Transactions.Conflicted.Hz,
+		LatencyCommit:         status.Cluster.LatencyProbe.CommitSeconds,
+		LatencyRead:           status.Cluster.LatencyProbe.ReadSeconds,
+		LatencyStart:          status.Cluster.LatencyProbe.TransactionStartSeconds,
+		TpsLimit:              status.Cluster.Qos.TransactionsPerSecondLimit,
+		ReleasedTps:           status.Cluster.Qos.ReleasedTransactionsPerSecond,
+		BatchTpsLimit:         status.Cluster.Qos.BatchTransactionsPerSecondLimit,
+		BatchReleasedTps:      status.Cluster.Qos.BatchReleasedTransactionsPerSecond,
+		MovingInFlightBytes:   status.Cluster.Data.MovingData.InFlightBytes,
+		MovingInQueueBytes:    status.Cluster.Data.MovingData.InQueueBytes,
+		ToleranceAvailability: status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability,
+		ToleranceData:         status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingData,
+		MinReplicasRemaining:  status.Cluster.Data.State.MinReplicasRemaining,
+		FullReplication:       status.Cluster.FullReplication,
+		DegradedProcesses:     status.Cluster.DegradedProcesses,
+		ReadP99Median:         readMedian,
+		ReadP99Worst:          readWorst,
+	}
+}
+
+// AppendHistory adds a metric to a history, dropping the oldest ones past maxHistory.
+func AppendHistory(history []HistoryMetric, metric HistoryMetric) []HistoryMetric {
+	history = append(history, metric)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	return history
+}
+
 func ShowMetricsScreen() {
 	const (
 		COL0 = 1
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apple/foundationdb/bindings/go/src/fdb"
+	"github.com/pkg/errors"
+)
+
+var (
+	clusterFiles listFlag
+	apiVersion   = flag.Int("api-version", 710, "FoundationDB API version")
+	interval     = flag.Duration("interval", time.Second, "delay between two polls of the status")
+	screenName   = flag.String("screen", "metrics", "initial screen: "+screenNames())
+	historySize  = flag.Int("max-history", maxHistory, "number of polls kept in the history")
+
+	statusFiles listFlag
+	statusDir   = flag.String("status-dir", "", "play back the *.json status snapshots of a directory")
+	fromStdin   = flag.Bool("stdin", false, "read a stream of status json documents from stdin")
+	record      = flag.String("record", "", "append every polled status to a compressed session file")
+	replay      = flag.String("replay", "", "replay a session file recorded with -record")
+	batch       bool
+	iterations  = flag.Int("n", 0, "number of samples to write in batch mode, 0 for no limit")
+	exportPath  = flag.String("export", "", "write the polled (or replayed) metrics to a CSV/TSV file and exit")
+	exportFmt   = flag.String("export-format", "", "format of exports: csv or tsv, guessed from the file extension by default")
+	promListen  = flag.String("prometheus-listen", "", "serve the polled status as prometheus metrics on this address, e.g. :9090")
+)
+
+func init() {
+	flag.Var(&clusterFiles, "cluster-file", "path of a cluster file, as `[name=]path`, repeat or separate with commas to monitor several clusters")
+	flag.Var(&statusFiles, "status-file", "read the status json from a file instead of the cluster, as `[name=]path`, can be repeated")
+	flag.BoolVar(&batch, "b", false, "batch mode: write a json line per poll to stdout instead of using the screen")
+	flag.BoolVar(&batch, "batch", false, "same as -b")
+}
+
+// envFlags are the flags that can also be set with an environment variable,
+// the command line having precedence.
+var envFlags = map[string]string{
+	"cluster-file": "FDBTOP_CLUSTER_FILE",
+	"api-version":  "FDBTOP_API_VERSION",
+	"interval":     "FDBTOP_INTERVAL",
+	"screen":       "FDBTOP_SCREEN",
+	"max-history":  "FDBTOP_MAX_HISTORY",
+}
+
+// listFlag is a flag that can be repeated, or given a comma separated list.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+func screenNames() string {
+	var names []string
+	for _, tab := range ScreenTabs {
+		names = append(names, tab.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+var initialMode = Metrics
+
+// ParseOptions parses the command line and the environment.
+func ParseOptions() error {
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: %s [options]\n\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintf(out, "\nEnvironment:\n")
+		names := make([]string, 0, len(envFlags))
+		for name := range envFlags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(out, "  %-20s same as -%s\n", envFlags[name], name)
+		}
+	}
+	flag.Parse()
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for name, env := range envFlags {
+		if value, ok := os.LookupEnv(env); ok && !set[name] {
+			if err := flag.Set(name, value); err != nil {
+				return errors.Wrapf(err, "invalid %s", env)
+			}
+		}
+	}
+
+	if *interval <= 0 {
+		return errors.Errorf("invalid interval %s", *interval)
+	}
+	if *historySize <= 0 {
+		return errors.Errorf("invalid history size %d", *historySize)
+	}
+	maxHistory = *historySize
+	if *record != "" && *replay != "" {
+		return errors.New("-record cannot be used with -replay, the replayed session is already recorded")
+	}
+
+	for _, tab := range ScreenTabs {
+		if tab.Name == strings.ToLower(*screenName) {
+			initialMode = tab.Mode
+			return nil
+		}
+	}
+	return errors.Errorf("unknown screen %q", *screenName)
+}
+
+// openClusters returns the clusters selected on the command line,
+// defaulting to the cluster of the default cluster file.
+func openClusters() ([]*Cluster, error) {
+	// Clusters are told apart by name on screen and in the exported metrics
+	seen := make(map[string]string)
+	clusterName := func(arg string) (string, string, error) {
+		name, path := ClusterName(arg)
+		if other, ok := seen[name]; ok {
+			return "", "", errors.Errorf("%s and %s are both named %q, name them with name=path", other, arg, name)
+		}
+		seen[name] = arg
+		return name, path, nil
+	}
+
+	switch {
+	case len(statusFiles) > 0:
+		var clusters []*Cluster
+		for _, arg := range statusFiles {
+			name, path, err := clusterName(arg)
+			if err != nil {
+				return nil, err
+			}
+			clusters = append(clusters, NewCluster(name, NewFileSource(path)))
+		}
+		return clusters, nil
+	case *statusDir != "":
+		source, err := NewDirectorySource(*statusDir)
+		if err != nil {
+			return nil, err
+		}
+		return []*Cluster{NewCluster(filepath.Base(*statusDir), source)}, nil
+	case *fromStdin:
+		return []*Cluster{NewCluster("stdin", NewReaderSource(os.Stdin))}, nil
+	}
+
+	// Different API versions may expose different runtime behaviors.
+	if err := fdb.APIVersion(*apiVersion); err != nil {
+		return nil, err
+	}
+
+	if len(clusterFiles) == 0 {
+		db, err := fdb.OpenDefault()
+		if err != nil {
+			return nil, err
+		}
+		return []*Cluster{NewCluster("default", NewDatabaseSource(db))}, nil
+	}
+
+	var clusters []*Cluster
+	for _, arg := range clusterFiles {
+		name, path, err := clusterName(arg)
+		if err != nil {
+			return nil, err
+		}
+		db, err := fdb.OpenDatabase(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot open %s", path)
+		}
+		clusters = append(clusters, NewCluster(name, NewDatabaseSource(db)))
+	}
+	return clusters, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func ShowOverviewScreen(now time.Time) {
+	const (
+		COL_NUM     = 1
+		COL_NAME    = COL_NUM + 3
+		LEN_NAME    = 20
+		COL_STATE   = COL_NAME + LEN_NAME + 1
+		COL_DATA    = COL_STATE + 26
+		COL_PERF    = COL_DATA + 28
+		COL_READS   = COL_PERF + 34
+		COL_WRITES  = COL_READS + 12
+		COL_COMMIT  = COL_WRITES + 12
+		COL_UPDATED = COL_COMMIT + 12
+	)
+
+	SetColor("DarkCyan")
+	WriteAtS(COL_NUM, 5, "#")
+	WriteAtS(COL_NAME, 5, "Cluster")
+	WriteAtS(COL_STATE, 5, "State")
+	WriteAtS(COL_DATA, 5, "Data")
+	WriteAtS(COL_PERF, 5, "Perf. limited by")
+	WriteAtS(COL_READS, 5, "Reads (Hz)")
+	WriteAtS(COL_WRITES, 5, "Writes (Hz)")
+	WriteAtS(COL_COMMIT, 5, "Commit (ms)")
+	WriteAtS(COL_UPDATED, 5, "Updated")
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+
+	y := 7
+	for i, cluster := range Clusters {
+		if y >= ScreenHeight-1 {
+			break
+		}
+		WriteAtS(0, y, emptyLine)
+
+		SetColorIf(cluster == ActiveCluster, "White", "Gray")
+		WriteAt(COL_NUM, y, "%d", i+1)
+		WriteAt(COL_NAME, y, "%-*s", LEN_NAME, trimMax(cluster.Name, LEN_NAME))
+
+		if cluster.Updated.IsZero() {
+			if cluster.Errors > 0 {
+				SetColor("Red")
+				text := fmt.Sprintf("NO STATUS    %v", cluster.LastError)
+				WriteAtS(COL_STATE, y, trimMax(text, ScreenWidth-COL_STATE-1))
+			} else {
+				SetColor("DarkGray")
+				WriteAtS(COL_STATE, y, "waiting...")
+			}
+			y++
+			continue
+		}
+
+		status := &cluster.Status
+		if cluster.Stale(now) {
+			SetColor("Red")
+			WriteAtS(COL_STATE, y, "STALE")
+		} else {
+			health := CheckHealth(status)
+			SetColor(health.Color())
+			WriteAtS(COL_STATE, y, trimMax(health.Label(), 25))
+		}
+
+		SetColorIf(status.Cluster.Data.State.Healthy, "Gray", "DarkYellow")
+		WriteAt(COL_DATA, y, "%-27s", trimMax(status.Cluster.Data.State.Name, 27))
+		SetColorIf(status.Cluster.Qos.PerformanceLimitedBy.Name == "workload", "Gray", "DarkYellow")
+		WriteAt(COL_PERF, y, "%-33s", trimMax(status.Cluster.Qos.PerformanceLimitedBy.Name, 33))
+
+		reads := status.Cluster.Workload.Operations.Reads.Hz
+		writes := status.Cluster.Workload.Operations.Writes.Hz
+		commit := status.Cluster.LatencyProbe.CommitSeconds
+		SetColor(FrequencyColor(reads))
+		WriteAt(COL_READS, y, "%10.0f", reads)
+		SetColor(FrequencyColor(writes))
+		WriteAt(COL_WRITES, y, "%11.0f", writes)
+		SetColor(LatencyColor(commit))
+		WriteAt(COL_COMMIT, y, "%11.3f", commit*1000)
+
+		age := now.Sub(cluster.Updated)
+		SetColorIf(cluster.Stale(now), "Red", "DarkGray")
+		WriteAt(COL_UPDATED, y, "%8s ago", age.Round(time.Second))
+		y++
+	}
+}
+
+func trimMax(s string, max int) string {
+	if max < 0 {
+		return ""
+	}
+	if r := []rune(s); len(r) > max {
+		return string(r[:max])
+	}
+	return s
+}
@@ -1,13 +1,115 @@
 package main
 
 import (
-	"math"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ProcessSortColumn is a column the rows of the Processes screen can be sorted by.
+type ProcessSortColumn struct {
+	Name       string
+	Descending bool // largest first, unless the order is reversed
+	Less       func(a, b *FdbProcess) bool
+}
+
+var ProcessSortColumns = []ProcessSortColumn{
+	{"address", false, func(a, b *FdbProcess) bool { return a.Address < b.Address }},
+	{"cpu", true, func(a, b *FdbProcess) bool { return a.Cpu.UsageCores < b.Cpu.UsageCores }},
+	{"memory", true, func(a, b *FdbProcess) bool {
+		return a.Memory.UsedBytes-a.Memory.UnusedAllocatedMemory < b.Memory.UsedBytes-b.Memory.UnusedAllocatedMemory
+	}},
+	{"recv", true, func(a, b *FdbProcess) bool { return a.Network.MegabitsReceived.Hz < b.Network.MegabitsReceived.Hz }},
+	{"sent", true, func(a, b *FdbProcess) bool { return a.Network.MegabitsSent.Hz < b.Network.MegabitsSent.Hz }},
+	{"queue", true, func(a, b *FdbProcess) bool { return ProcessQueueSize(a) < ProcessQueueSize(b) }},
+	{"disk busy", true, func(a, b *FdbProcess) bool { return a.Disk.Busy < b.Disk.Busy }},
+	{"version", true, func(a, b *FdbProcess) bool { return VersionLess(a.Version, b.Version) }},
+	{"uptime", false, func(a, b *FdbProcess) bool { return a.UptimeSeconds < b.UptimeSeconds }},
+}
+
+var (
+	processSort        int  // index of the sort column in ProcessSortColumns
+	processSortReverse bool // reverse order of the sort column
+	processFlat        bool // one row per process, instead of grouping them by machine
 )
 
-func ShowProcessesScreen(status FdbStatus) {
+// ProcessLess returns the order of the rows of the Processes screen, ties being sorted by address.
+func ProcessLess() func(a, b *FdbProcess) bool {
+	column := ProcessSortColumns[processSort]
+	descending := column.Descending != processSortReverse
+	return func(a, b *FdbProcess) bool {
+		if column.Less(a, b) {
+			return !descending
+		}
+		if column.Less(b, a) {
+			return descending
+		}
+		return a.Address < b.Address
+	}
+}
+
+// ProcessSortLabel describes the order of the rows of the Processes screen.
+func ProcessSortLabel() string {
+	column := ProcessSortColumns[processSort]
+	if column.Descending != processSortReverse {
+		return "sort: " + column.Name + " ▼"
+	}
+	return "sort: " + column.Name + " ▲"
+}
+
+// VersionLess compares versions like 7.1.40 by their numeric components, so that 7.9 is before 7.10.
+func VersionLess(a, b string) bool {
+	x, y := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] == y[i] {
+			continue
+		}
+		n, errX := strconv.Atoi(x[i])
+		m, errY := strconv.Atoi(y[i])
+		if errX != nil || errY != nil {
+			return x[i] < y[i]
+		}
+		if n != m {
+			return n < m
+		}
+	}
+	return len(x) < len(y)
+}
+
+// ProcessQueueSize returns the bytes not yet durable on the storage and log roles of a process.
+func ProcessQueueSize(proc *FdbProcess) int64 {
+	var queueSize int64
+	for _, role := range proc.Roles {
+		if role.Role == StorageRoleMetrics || role.Role == LogRoleMetrics {
+			queueSize += role.QueueBytes()
+		}
+	}
+	return queueSize
+}
+
+// HandleProcessesKey processes the keys sorting the Processes screen, and returns false for any other key.
+func HandleProcessesKey(ev *tcell.EventKey) bool {
+	switch ev.Rune() {
+	case '>':
+		processSort = (processSort + 1) % len(ProcessSortColumns)
+		processSortReverse = false
+	case '<':
+		processSort = (processSort + len(ProcessSortColumns) - 1) % len(ProcessSortColumns)
+		processSortReverse = false
+	case 'I':
+		processSortReverse = !processSortReverse
+	case 'v':
+		processFlat = !processFlat
+	default:
+		return false
+	}
+	return true
+}
+
+func ShowProcessesScreen(status FdbStatus, history map[string][]ProcessSample) {
 	const (
 		CPU_BARSZ     = 10
 		MEM_BARSZ     = 5
@@ -31,10 +133,19 @@ func ShowProcessesScreen(status FdbStatus) {
 		COL_ROLES     = COL_HDD + LEN_HDD + BAR
 		LEN_ROLES     = 11
 		COL9          = COL_ROLES + LEN_ROLES + BAR
+		COL_VERSION   = COL9 + 11 + BAR
+		COL_TREND     = COL_VERSION + 8 + BAR
+		TREND_WIDTH   = 12
 	)
 
 	SetColor("DarkCyan")
-	WriteAtS(COL_HOST, 5, "Address (port)")
+	if processFlat {
+		WriteAtS(COL_HOST, 5, "Address       ")
+		WriteAtS(COL_VERSION, 5, " Version")
+	} else {
+		WriteAtS(COL_HOST, 5, "Address (port)")
+		WriteAtS(COL_VERSION, 5, "        ")
+	}
 	WriteAtS(COL_NET, 5, "Network (Mbps)")
 	WriteAtS(COL_NET, 6, " Cnx     Recv     Sent")
 	WriteAtS(COL_CPU, 5, "CPU Activity")
@@ -46,8 +157,7 @@ func ShowProcessesScreen(status FdbStatus) {
 	WriteAtS(COL_DISK, 6, "   Queue Queried Mutated   HDD Busy")
 	WriteAtS(COL_ROLES, 5, "Roles")
 	WriteAtS(COL9, 5, "Uptime")
-
-	LastProcessYMax = 0
+	WriteAtS(COL_TREND, 5, "CPU trend")
 
 	if debugLayout {
 		SetColor("DarkGray")
@@ -67,22 +177,167 @@ func ShowProcessesScreen(status FdbStatus) {
 		//TODO display error message?
 		return
 	} else {
-		WriteAt(COL_HOST, 4, "                  ")
+		SetColor("DarkGray")
+		WriteAt(COL_HOST, 4, "%-18s", ProcessSortLabel())
 	}
 
 	var maxVersion string
 	for _, p := range status.Cluster.Processes {
-		if p.Version > maxVersion {
+		if VersionLess(maxVersion, p.Version) {
 			maxVersion = p.Version
 		}
 	}
 
-	ScreenWidth, ScreenHeight := screen.Size()
+	ScreenWidth, _ := screen.Size()
 	emptyLine := strings.Repeat(" ", ScreenWidth)
 
 	roleMap := &RoleMap{}
 
-	y := 7
+	// showProcess draws the row of a process, labeled with its port, or its address in the flat view
+	showProcess := func(y int, proc FdbProcess, machine *FdbMachine, selected bool) {
+		if selected {
+			SetBackground("DarkBlue")
+			defer SetBackground("Black")
+		}
+		p := strings.Index(proc.Address, ":")
+		port := ""
+		if p >= 0 {
+			port = proc.Address[p+1:]
+		} else {
+			port = proc.Address
+		}
+
+		roleMap.Reset()
+		var mutationBytes, queriedBytes float64
+		queueSize := ProcessQueueSize(&proc)
+		for _, role := range proc.Roles {
+			roleMap.Add(role.Role)
+			if role.Role == StorageRoleMetrics {
+				mutationBytes += role.MutationBytes.Hz
+				queriedBytes += role.BytesQueried.Hz
+			}
+		}
+
+		SetColor("DarkGray")
+		WriteAtS(COL_HOST, y, strings.ReplaceAll("_______ | ______ | ____ ________ ________ | _____% __________ | _____ / _____ _____ | ________ _______ _______ | _____% __________ | ___________ |", "_", " "))
+
+		if processFlat {
+			SetColorIf(proc.Version != maxVersion, "DarkRed", "DarkGray")
+			WriteAt(COL_VERSION, y, "%8s", proc.Version)
+
+			SetColorIf(proc.Excluded, "DarkRed", "Gray")
+			WriteAt(COL_HOST, y, "%-16s", trimMax(proc.Address, LEN_HOST))
+		} else {
+			SetColorIf(proc.Version != maxVersion, "DarkRed", "DarkGray")
+			WriteAt(COL_HOST+10, y, "%6s", proc.Version)
+
+			SetColorIf(proc.Excluded, "DarkRed", "Gray")
+			WriteAt(COL_HOST, y, "%7s", port)
+		}
+
+		SetColor(MapConnectionsToColor(proc.Network.CurrentConnections))
+		WriteAt(COL_NET, y, "%4d", proc.Network.CurrentConnections)
+		SetColor(MapMegabitsToColor(proc.Network.MegabitsReceived.Hz))
+		//WriteAt( COL_NET+5, y, "%8.2f", Nice(proc.Network.MegabitsReceived.Hz, "-", 0.005, "~"))
+		WriteAt(COL_NET+5, y, "%8s", Nice(proc.Network.MegabitsReceived.Hz, "-", 0.005, "~"))
+		SetColor(MapMegabitsToColor(proc.Network.MegabitsSent.Hz))
+		//WriteAt( COL_NET+14, y, "%8.2f", Nice(proc.Network.MegabitsSent.Hz, "-", 0.005, "~"))
+		WriteAt(COL_NET+14, y, "%8s", Nice(proc.Network.MegabitsSent.Hz, "-", 0.005, "~"))
+
+		cpuUsage := proc.Cpu.UsageCores
+		if cpuUsage >= 0.95 {
+			SetColor("DarkRed")
+		} else if cpuUsage >= 0.75 {
+			SetColor("DarkYellow")
+		} else if cpuUsage >= 0.2 {
+			SetColor("Gray")
+		} else {
+			SetColor("DarkGray")
+		}
+		WriteAt(COL_CPU, y, "%5.1f", cpuUsage*100)
+		if cpuUsage >= 0.95 {
+			SetColor("DarkRed")
+		} else if cpuUsage >= 0.75 {
+			SetColor("DarkYellow")
+		} else {
+			SetColor("DarkGreen")
+		}
+		WriteAt(COL_CPU+7, y, "%-10s", BarGraph(proc.Cpu.UsageCores, 1, CPU_BARSZ, '|', ":", "."))
+
+		memoryUsed := proc.Memory.UsedBytes - proc.Memory.UnusedAllocatedMemory
+		memoryAllocated := proc.Memory.UsedBytes
+		SetColor(MapMemoryToColor(memoryUsed))
+		WriteAt(COL_MEM_USED, y, "%5.1f", GigaBytes(memoryUsed))
+		SetColor(MapMemoryToColor(memoryAllocated))
+		WriteAt(COL_MEM_TOTAL, y, "%5.1f", GigaBytes(memoryAllocated))
+		if float64(memoryUsed) >= 0.9*float64(proc.Memory.LimitBytes) {
+			SetColor("DarkRed")
+		} else if float64(memoryUsed) >= 0.75*float64(proc.Memory.LimitBytes) {
+			SetColor("DarkYellow")
+		} else {
+			SetColor("DarkGreen")
+		}
+		WriteAt(COL_MEM_TOTAL+6, y, "%-5s", BarGraph(float64(memoryUsed), float64(machine.Memory.CommittedBytes), MEM_BARSZ, '|', ":", "."))
+
+		if roleMap.Log || roleMap.Storage {
+			SetColor(MapQueueSizeToColor(float64(queueSize)))
+			WriteAt(COL_DISK, y, "%8s", FriendlyBytes(queueSize))
+		}
+		if roleMap.Storage {
+			SetColor(MapDiskOpsToColor(queriedBytes))
+			WriteAt(COL_DISK+9, y, "%7.1f", MegaBytes(int64(queriedBytes)))
+			SetColor(MapDiskOpsToColor(mutationBytes))
+			WriteAt(COL_DISK+17, y, "%7.1f", MegaBytes(int64(mutationBytes)))
+		}
+
+		SetColor("Gray")
+		WriteAt(COL_HDD, y, "%5.1f", proc.Disk.Busy*100)
+		if proc.Disk.Busy == 0.0 {
+			SetColor("DarkGray")
+		} else if proc.Disk.Busy >= 0.95 {
+			SetColor("DarkRed")
+		} else {
+			SetColor("DarkGreen")
+		}
+		WriteAt(COL_HDD+7, y, "%-10s", strings.Repeat("|", Bar(proc.Disk.Busy, 1, 10)))
+
+		SetColor("Gray")
+		WriteAt(COL_ROLES, y, "%11s", roleMap.String())
+
+		SetColor("DarkGray")
+		WriteAt(COL9, y, "%11s", time.Duration(proc.UptimeSeconds)*time.Second)
+
+		ShowTrend(COL_TREND, y, ProcessSeries(history[proc.Address], func(s *ProcessSample) float64 { return s.Cpu }), TREND_WIDTH)
+	}
+
+	scroll := Scrolls[Processes]
+	scroll.Begin(7)
+	row := 0
+	less := ProcessLess()
+
+	if processFlat {
+		var procs []FdbProcess
+		for _, p := range status.Cluster.Processes {
+			if processFilter.Matches(&p, nil) {
+				procs = append(procs, p)
+			}
+		}
+		sort.Slice(procs, func(i, j int) bool {
+			return less(&procs[i], &procs[j])
+		})
+		for _, proc := range procs {
+			selected := scroll.Item(row, proc.Address)
+			if y, ok := scroll.Line(row); ok {
+				machine := status.Cluster.Machines[proc.MachineId]
+				showProcess(y, proc, &machine, selected)
+			}
+			row++
+		}
+		ShowProcessFilter(len(procs), len(status.Cluster.Processes), "processes")
+		scroll.End(row)
+		return
+	}
+
 	//machines := status.Cluster.Machines
 	var machines []FdbMachine
 	for id, m := range status.Cluster.Machines {
@@ -90,21 +345,43 @@ func ShowProcessesScreen(status FdbStatus) {
 		machines = append(machines, m)
 	}
 
+	byMachine := make(map[string][]FdbProcess)
+	for _, p := range status.Cluster.Processes {
+		byMachine[p.MachineId] = append(byMachine[p.MachineId], p)
+	}
+	for _, procs := range byMachine {
+		sort.Slice(procs, func(i, j int) bool {
+			return less(&procs[i], &procs[j])
+		})
+	}
+
+	// Machines are sorted by their first process
 	sort.Slice(machines, func(i, j int) bool {
+		a, b := byMachine[machines[i].Id], byMachine[machines[j].Id]
+		if len(a) > 0 && len(b) > 0 {
+			return less(&a[0], &b[0])
+		}
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
 		return machines[i].Address < machines[j].Address
 	})
 
+	shownProcesses := 0
 	for _, machine := range machines {
-		var procs []FdbProcess
-		for _, p := range status.Cluster.Processes {
-			if p.MachineId == machine.Id {
-				procs = append(procs, p)
+		procs := byMachine[machine.Id]
+
+		// The totals of a machine are over all its processes, even those filtered out
+		var shown []FdbProcess
+		for _, p := range procs {
+			if processFilter.Matches(&p, nil) {
+				shown = append(shown, p)
 			}
 		}
-
-		sort.Slice(procs, func(i, j int) bool {
-			return procs[i].Address < procs[j].Address
-		})
+		if len(shown) == 0 && processFilter.Text != "" {
+			continue
+		}
+		shownProcesses += len(shown)
 
 		var storageBytes, queueDiskBytes, totalCnx, totalQueueSize int64
 		var totalDiskBusy, totalMutationBytes, totalQueriedBytes float64
@@ -119,7 +396,7 @@ func ShowProcessesScreen(status FdbStatus) {
 				case StorageRoleMetrics:
 					totalMutationBytes += role.MutationBytes.Hz
 					totalQueriedBytes += role.BytesQueried.Hz
-					totalQueueSize += int64(role.InputBytes.Counter - role.DurableBytes.Counter)
+					totalQueueSize += role.QueueBytes()
 					storageBytes += role.StoredBytes
 				case LogRoleMetrics:
 					queueDiskBytes += role.QueueDiskUsedBytes
@@ -129,181 +406,80 @@ func ShowProcessesScreen(status FdbStatus) {
 
 		totalDiskBusy /= float64(len(procs))
 
-		SetColor("DarkGray")
-		WriteAt(1, y, "%s", strings.ReplaceAll("                 | ____ ________ ________ | _____% __________ | _____ / _____ _____ | ________ _______ _______ | _____% __________ | ___________ | ", "_", " "))
-
-		SetColor("White")
-		WriteAt(COL_HOST, y, "%s", machine.Address)
-		SetColor(MapConnectionsToColor(totalCnx))
-		WriteAt(COL_NET, y, "%4.0d", totalCnx)
-		SetColor(MapMegabitsToColor(machine.Network.MegabitsReceived.Hz))
-		WriteAt(COL_NET+5, y, "%8.2f", machine.Network.MegabitsReceived.Hz)
-		SetColor(MapMegabitsToColor(machine.Network.MegabitsSent.Hz))
-		WriteAt(COL_NET+14, y, "%8.2f", machine.Network.MegabitsSent.Hz)
+		if y, ok := scroll.Line(row); ok {
+			SetColor("DarkGray")
+			WriteAt(1, y, "%s", strings.ReplaceAll("                 | ____ ________ ________ | _____% __________ | _____ / _____ _____ | ________ _______ _______ | _____% __________ | ___________ | ", "_", " "))
 
-		WriteAt(COL_CPU, y, "%5.1f", machine.Cpu.LogicalCoreUtilization*100)
+			SetColor("White")
+			WriteAt(COL_HOST, y, "%s", machine.Address)
+			SetColor(MapConnectionsToColor(totalCnx))
+			WriteAt(COL_NET, y, "%4.0d", totalCnx)
+			SetColor(MapMegabitsToColor(machine.Network.MegabitsReceived.Hz))
+			WriteAt(COL_NET+5, y, "%8.2f", machine.Network.MegabitsReceived.Hz)
+			SetColor(MapMegabitsToColor(machine.Network.MegabitsSent.Hz))
+			WriteAt(COL_NET+14, y, "%8.2f", machine.Network.MegabitsSent.Hz)
 
-		WriteAt(COL_MEM_USED, y, "%5.1f", GigaBytes(machine.Memory.CommittedBytes))
-		WriteAt(COL_MEM_TOTAL, y, "%5.1f", GigaBytes(machine.Memory.TotalBytes))
+			WriteAt(COL_CPU, y, "%5.1f", machine.Cpu.LogicalCoreUtilization*100)
 
-		SetColor("DarkGray")
-		WriteAt(COL_ROLES, y, "%11s", roleMap.String())
+			WriteAt(COL_MEM_USED, y, "%5.1f", GigaBytes(machine.Memory.CommittedBytes))
+			WriteAt(COL_MEM_TOTAL, y, "%5.1f", GigaBytes(machine.Memory.TotalBytes))
 
-		if machine.Cpu.LogicalCoreUtilization >= 0.9 {
-			SetColor("DarkRed")
-		} else {
-			SetColor("DarkGreen")
-		}
-		WriteAt(COL_CPU+7, y, "%-10s", BarGraph(machine.Cpu.LogicalCoreUtilization, 1, CPU_BARSZ, '=', ":", ".")) // 1 = all the (logical) cores
-
-		memRatio := float64(machine.Memory.CommittedBytes) / float64(machine.Memory.TotalBytes)
-		if memRatio >= 0.95 {
-			SetColor("Red")
-		} else if memRatio >= 0.79 {
-			SetColor("DarkYellow")
-		} else {
-			SetColor("Green")
-		}
-		WriteAt(COL_MEM_TOTAL+6, y, "%-5s", BarGraph(float64(machine.Memory.CommittedBytes), float64(machine.Memory.TotalBytes), MEM_BARSZ, '=', ":", "."))
-
-		if roleMap.Log || roleMap.Storage {
-			SetColor(MapQueueSizeToColor(float64(totalQueueSize)))
-			WriteAt(COL_DISK, y, "%8s", FriendlyBytes(totalQueueSize))
-		}
-		if roleMap.Storage {
-			SetColor(MapDiskOpsToColor(totalQueriedBytes))
-			WriteAt(COL_DISK+9, y, "%7.1f", MegaBytes(int64(totalQueriedBytes)))
-			SetColor(MapDiskOpsToColor(totalMutationBytes))
-			WriteAt(COL_DISK+17, y, "%7.1f", MegaBytes(int64(totalMutationBytes)))
-		}
-
-		SetColor("Gray")
-		WriteAt(COL_HDD, y, "%5.1f", totalDiskBusy*100)
-		if totalDiskBusy == 0.0 {
 			SetColor("DarkGray")
-		} else if totalDiskBusy >= 0.95 {
-			SetColor("DarkRed")
-		} else {
-			SetColor("DarkGreen")
-		}
-		WriteAt(COL_HDD+7, y, "%-10s", BarGraph(totalDiskBusy, 1, 10, '=', ":", "."))
-
-		y++
+			WriteAt(COL_ROLES, y, "%11s", roleMap.String())
 
-		for _, proc := range procs {
-			p := strings.Index(proc.Address, ":")
-			port := ""
-			if p >= 0 {
-				port = proc.Address[p+1:]
+			if machine.Cpu.LogicalCoreUtilization >= 0.9 {
+				SetColor("DarkRed")
 			} else {
-				port = proc.Address
+				SetColor("DarkGreen")
 			}
+			WriteAt(COL_CPU+7, y, "%-10s", BarGraph(machine.Cpu.LogicalCoreUtilization, 1, CPU_BARSZ, '=', ":", ".")) // 1 = all the (logical) cores
 
-			roleMap.Reset()
-			var mutationBytes, queriedBytes float64
-			var queueSize int64
-			for _, role := range proc.Roles {
-				roleMap.Add(role.Role)
-				if role.Role == StorageRoleMetrics {
-					mutationBytes += role.MutationBytes.Hz
-					queriedBytes += role.BytesQueried.Hz
-					queueSize += int64(role.InputBytes.Counter - role.DurableBytes.Counter)
-				} else if role.Role == LogRoleMetrics {
-					queueSize += int64(role.InputBytes.Counter - role.DurableBytes.Counter)
-				}
+			memRatio := float64(machine.Memory.CommittedBytes) / float64(machine.Memory.TotalBytes)
+			if memRatio >= 0.95 {
+				SetColor("Red")
+			} else if memRatio >= 0.79 {
+				SetColor("DarkYellow")
+			} else {
+				SetColor("Green")
 			}
+			WriteAt(COL_MEM_TOTAL+6, y, "%-5s", BarGraph(float64(machine.Memory.CommittedBytes), float64(machine.Memory.TotalBytes), MEM_BARSZ, '=', ":", "."))
 
-			if y < ScreenHeight {
-				SetColor("DarkGray")
-				WriteAtS(COL_HOST, y, strings.ReplaceAll("_______ | ______ | ____ ________ ________ | _____% __________ | _____ / _____ _____ | ________ _______ _______ | _____% __________ | ___________ |", "_", " "))
-
-				SetColorIf(proc.Version != maxVersion, "DarkRed", "DarkGray")
-				WriteAt(COL_HOST+10, y, "%6s", proc.Version)
-
-				SetColorIf(proc.Excluded, "DarkRed", "Gray")
-				WriteAt(COL_HOST, y, "%7s", port)
-
-				SetColor(MapConnectionsToColor(proc.Network.CurrentConnections))
-				WriteAt(COL_NET, y, "%4d", proc.Network.CurrentConnections)
-				SetColor(MapMegabitsToColor(proc.Network.MegabitsReceived.Hz))
-				//WriteAt( COL_NET+5, y, "%8.2f", Nice(proc.Network.MegabitsReceived.Hz, "-", 0.005, "~"))
-				WriteAt(COL_NET+5, y, "%8s", Nice(proc.Network.MegabitsReceived.Hz, "-", 0.005, "~"))
-				SetColor(MapMegabitsToColor(proc.Network.MegabitsSent.Hz))
-				//WriteAt( COL_NET+14, y, "%8.2f", Nice(proc.Network.MegabitsSent.Hz, "-", 0.005, "~"))
-				WriteAt(COL_NET+14, y, "%8s", Nice(proc.Network.MegabitsSent.Hz, "-", 0.005, "~"))
-
-				cpuUsage := proc.Cpu.UsageCores
-				if cpuUsage >= 0.95 {
-					SetColor("DarkRed")
-				} else if cpuUsage >= 0.75 {
-					SetColor("DarkYellow")
-				} else if cpuUsage >= 0.2 {
-					SetColor("Gray")
-				} else {
-					SetColor("DarkGray")
-				}
-				WriteAt(COL_CPU, y, "%5.1f", cpuUsage*100)
-				if cpuUsage >= 0.95 {
-					SetColor("DarkRed")
-				} else if cpuUsage >= 0.75 {
-					SetColor("DarkYellow")
-				} else {
-					SetColor("DarkGreen")
-				}
-				WriteAt(COL_CPU+7, y, "%-10s", BarGraph(proc.Cpu.UsageCores, 1, CPU_BARSZ, '|', ":", "."))
-
-				memoryUsed := proc.Memory.UsedBytes - proc.Memory.UnusedAllocatedMemory
-				memoryAllocated := proc.Memory.UsedBytes
-				SetColor(MapMemoryToColor(memoryUsed))
-				WriteAt(COL_MEM_USED, y, "%5.1f", GigaBytes(memoryUsed))
-				SetColor(MapMemoryToColor(memoryAllocated))
-				WriteAt(COL_MEM_TOTAL, y, "%5.1f", GigaBytes(memoryAllocated))
-				if float64(memoryUsed) >= 0.9*float64(proc.Memory.LimitBytes) {
-					SetColor("DarkRed")
-				} else if float64(memoryUsed) >= 0.75*float64(proc.Memory.LimitBytes) {
-					SetColor("DarkYellow")
-				} else {
-					SetColor("DarkGreen")
-				}
-				WriteAt(COL_MEM_TOTAL+6, y, "%-5s", BarGraph(float64(memoryUsed), float64(machine.Memory.CommittedBytes), MEM_BARSZ, '|', ":", "."))
-
-				if roleMap.Log || roleMap.Storage {
-					SetColor(MapQueueSizeToColor(float64(queueSize)))
-					WriteAt(COL_DISK, y, "%8s", FriendlyBytes(queueSize))
-				}
-				if roleMap.Storage {
-					SetColor(MapDiskOpsToColor(queriedBytes))
-					WriteAt(COL_DISK+9, y, "%7.1f", MegaBytes(int64(queriedBytes)))
-					SetColor(MapDiskOpsToColor(mutationBytes))
-					WriteAt(COL_DISK+17, y, "%7.1f", MegaBytes(int64(mutationBytes)))
-				}
-
-				SetColor("Gray")
-				WriteAt(COL_HDD, y, "%5.1f", proc.Disk.Busy*100)
-				if proc.Disk.Busy == 0.0 {
-					SetColor("DarkGray")
-				} else if proc.Disk.Busy >= 0.95 {
-					SetColor("DarkRed")
-				} else {
-					SetColor("DarkGreen")
-				}
-				WriteAt(COL_HDD+7, y, "%-10s", strings.Repeat("|", Bar(proc.Disk.Busy, 1, 10)))
-
-				SetColor("Gray")
-				WriteAt(COL_ROLES, y, "%11s", roleMap.String())
+			if roleMap.Log || roleMap.Storage {
+				SetColor(MapQueueSizeToColor(float64(totalQueueSize)))
+				WriteAt(COL_DISK, y, "%8s", FriendlyBytes(totalQueueSize))
+			}
+			if roleMap.Storage {
+				SetColor(MapDiskOpsToColor(totalQueriedBytes))
+				WriteAt(COL_DISK+9, y, "%7.1f", MegaBytes(int64(totalQueriedBytes)))
+				SetColor(MapDiskOpsToColor(totalMutationBytes))
+				WriteAt(COL_DISK+17, y, "%7.1f", MegaBytes(int64(totalMutationBytes)))
+			}
 
+			SetColor("Gray")
+			WriteAt(COL_HDD, y, "%5.1f", totalDiskBusy*100)
+			if totalDiskBusy == 0.0 {
 				SetColor("DarkGray")
-				WriteAt(COL9, y, "%11s", time.Duration(proc.UptimeSeconds)*time.Second)
+			} else if totalDiskBusy >= 0.95 {
+				SetColor("DarkRed")
+			} else {
+				SetColor("DarkGreen")
 			}
+			WriteAt(COL_HDD+7, y, "%-10s", BarGraph(totalDiskBusy, 1, 10, '=', ":", "."))
+		}
+		row++
 
-			y++
+		for _, proc := range shown {
+			selected := scroll.Item(row, proc.Address)
+			if y, ok := scroll.Line(row); ok {
+				showProcess(y, proc, &machine, selected)
+			}
+			row++
 		}
-		WriteAtS(COL_HOST, y, emptyLine)
-		y++
-	}
-	y = int(math.Min(float64(y), float64(ScreenHeight)))
-	for ; y < LastProcessYMax; y++ {
-		WriteAtS(COL_HOST, y, emptyLine)
+		if y, ok := scroll.Line(row); ok {
+			WriteAtS(0, y, emptyLine)
+		}
+		row++
 	}
-	LastProcessYMax = y
+	ShowProcessFilter(shownProcesses, len(status.Cluster.Processes), "processes")
+	scroll.End(row)
 }
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestVersionLess(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		less bool
+	}{
+		{"7.9.0", "7.10.0", true},
+		{"7.10.0", "7.9.0", false},
+		{"7.1.40", "7.1.9", false},
+		{"7.1", "7.1.0", true},
+		{"7.1.0", "7.1.0", false},
+		{"", "6.3.24", true},
+	} {
+		if VersionLess(tt.a, tt.b) != tt.less {
+			t.Errorf("VersionLess(%q, %q): expected %t", tt.a, tt.b, tt.less)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+// ProcessSample is the activity of a process at a poll.
+type ProcessSample struct {
+	Cpu       float64 // cores
+	Memory    int64   // bytes used
+	DiskBusy  float64
+	Recv      float64 // Mbps
+	Sent      float64 // Mbps
+	QueueSize int64   // bytes not yet durable on its storage and log roles
+}
+
+// NewProcessSample returns the activity of a process in a status.
+func NewProcessSample(proc *FdbProcess) ProcessSample {
+	return ProcessSample{
+		Cpu:       proc.Cpu.UsageCores,
+		Memory:    proc.Memory.UsedBytes - proc.Memory.UnusedAllocatedMemory,
+		DiskBusy:  proc.Disk.Busy,
+		Recv:      proc.Network.MegabitsReceived.Hz,
+		Sent:      proc.Network.MegabitsSent.Hz,
+		QueueSize: ProcessQueueSize(proc),
+	}
+}
+
+// AppendProcessHistory adds the activity of the processes of a status to their series,
+// keeping at most maxHistory samples per process. Processes no longer in the status are dropped.
+func AppendProcessHistory(history map[string][]ProcessSample, status *FdbStatus) map[string][]ProcessSample {
+	updated := make(map[string][]ProcessSample, len(status.Cluster.Processes))
+	for _, proc := range status.Cluster.Processes {
+		samples := append(history[proc.Address], NewProcessSample(&proc))
+		if len(samples) > maxHistory {
+			samples = samples[len(samples)-maxHistory:]
+		}
+		updated[proc.Address] = samples
+	}
+	return updated
+}
+
+// ProcessSeries returns a value of every sample of a process.
+func ProcessSeries(samples []ProcessSample, get func(s *ProcessSample) float64) []float64 {
+	values := make([]float64, len(samples))
+	for i := range samples {
+		values[i] = get(&samples[i])
+	}
+	return values
+}
+
+// sparkBlocks are the characters of a sparkline, from the lowest to the highest value.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws the last width values of a series, scaled from zero to their maximum.
+func Sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	line := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if max > 0 && v > 0 {
+			level = int(v / max * float64(len(sparkBlocks)-1))
+		}
+		line[i] = sparkBlocks[level]
+	}
+	return string(line)
+}
+
+// RoleSample is the activity of a role at a poll.
+type RoleSample struct {
+	QueueSize     int64   // bytes not yet durable
+	DataLag       float64 // seconds
+	DurabilityLag float64 // seconds
+	Queried       float64 // bytes/s
+	Mutation      float64 // bytes/s
+}
+
+// NewRoleSample returns the activity of a role in a status.
+func NewRoleSample(role *FdbRole) RoleSample {
+	return RoleSample{
+		QueueSize:     role.QueueBytes(),
+		DataLag:       role.DataLag.Seconds,
+		DurabilityLag: role.DurabilityLag.Seconds,
+		Queried:       role.BytesQueried.Hz,
+		Mutation:      role.MutationBytes.Hz,
+	}
+}
+
+// RoleKey identifies a role from one poll to the next, by its ID,
+// or by its process and kind for the roles without ID.
+func RoleKey(proc *FdbProcess, role *FdbRole) string {
+	if role.Id != "" {
+		return role.Id
+	}
+	return proc.Address + "/" + role.Role
+}
+
+// AppendRoleHistory adds the activity of the roles of a status to their series,
+// keeping at most maxHistory samples per role. Roles no longer in the status are dropped.
+func AppendRoleHistory(history map[string][]RoleSample, status *FdbStatus) map[string][]RoleSample {
+	updated := make(map[string][]RoleSample)
+	for _, proc := range status.Cluster.Processes {
+		for i := range proc.Roles {
+			key := RoleKey(&proc, &proc.Roles[i])
+			samples := append(history[key], NewRoleSample(&proc.Roles[i]))
+			if len(samples) > maxHistory {
+				samples = samples[len(samples)-maxHistory:]
+			}
+			updated[key] = samples
+		}
+	}
+	return updated
+}
+
+// RoleSeries returns a value of every sample of a role.
+func RoleSeries(samples []RoleSample, get func(s *RoleSample) float64) []float64 {
+	values := make([]float64, len(samples))
+	for i := range samples {
+		values[i] = get(&samples[i])
+	}
+	return values
+}
+
+// trendSamples is the number of samples before the last one that its trend is measured against.
+const trendSamples = 5
+
+// Trend returns an arrow telling if the last value of a series is above, below or close to
+// the mean of the values before it, with its color, rising being a warning.
+func Trend(values []float64) (string, string) {
+	if len(values) < 2 {
+		return " ", "DarkGray"
+	}
+	last := values[len(values)-1]
+	previous := values[:len(values)-1]
+	if len(previous) > trendSamples {
+		previous = previous[len(previous)-trendSamples:]
+	}
+	mean := 0.0
+	for _, v := range previous {
+		mean += v
+	}
+	mean /= float64(len(previous))
+
+	switch {
+	case last > mean*1.1:
+		return "↑", "Yellow"
+	case last < mean*0.9:
+		return "↓", "Green"
+	default:
+		return "→", "DarkGray"
+	}
+}
+
+// ShowTrend draws the sparkline of the last values of a series, followed by the arrow of its trend.
+func ShowTrend(x int, y int, values []float64, width int) {
+	SetColor("DarkGreen")
+	WriteAt(x, y, "%-*s", width, Sparkline(values, width))
+	arrow, color := Trend(values)
+	SetColor(color)
+	WriteAtS(x+width+1, y, arrow)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Prompt is a line of text typed over the bottom bar.
+type Prompt struct {
+	label string
+	text  []rune
+	done  func(text string)
+}
+
+// prompt is the active prompt, if any.
+var prompt *Prompt
+
+// OpenPrompt starts reading a line of text, done is called with it once Enter is pressed.
+func OpenPrompt(label string, text string, done func(text string)) {
+	prompt = &Prompt{label: label, text: []rune(text), done: done}
+}
+
+// HandlePromptKey feeds a key to the active prompt, and returns false if there is none.
+func HandlePromptKey(ev *tcell.EventKey) bool {
+	if prompt == nil {
+		return false
+	}
+
+	p := prompt
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		prompt = nil
+	case tcell.KeyEnter:
+		prompt = nil
+		p.done(string(p.text))
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(p.text) > 0 {
+			p.text = p.text[:len(p.text)-1]
+		}
+	case tcell.KeyCtrlU:
+		p.text = nil
+	case tcell.KeyRune:
+		p.text = append(p.text, ev.Rune())
+	}
+	if prompt == nil {
+		screen.HideCursor()
+	}
+	return true
+}
+
+func ShowPrompt() {
+	if prompt == nil {
+		return
+	}
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	SetBackground("DarkBlue")
+	SetColor("White")
+	WriteAtS(0, ScreenHeight-1, strings.Repeat(" ", ScreenWidth))
+	WriteAt(0, ScreenHeight-1, " %s %s", prompt.label, string(prompt.text))
+	SetBackground("Black")
+	screen.ShowCursor(len([]rune(prompt.label))+2+len(prompt.text), ScreenHeight-1)
+}
+
+var (
+	notice      string
+	noticeUntil time.Time
+)
+
+// Notify displays a short message on the right of the bottom bar for a few seconds.
+func Notify(message string) {
+	notice = message
+	noticeUntil = time.Now().Add(5 * time.Second)
+}
+
+func ShowNotice() bool {
+	if notice == "" || time.Now().After(noticeUntil) {
+		return false
+	}
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	SetBackground("DarkCyan")
+	SetColor("White")
+	WriteAt(ScreenWidth-len([]rune(notice))-2, ScreenHeight-1, " %s ", notice)
+	SetBackground("Black")
+	return true
+}
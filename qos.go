@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShowQosScreen displays what ratekeeper is limiting the cluster on, and the History
+// of the transaction rates it released against its limits.
+func ShowQosScreen(status FdbStatus) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 12
+		COL2 = COL1 + 12 + MAX_RW_WIDTH + 10
+		COL3 = COL2 + 12 + MAX_RW_WIDTH + 10
+	)
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+	qos := &status.Cluster.Qos
+
+	for y := 5; y <= 9; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 5, "Limited by")
+	WriteAtS(COL0, 6, "Batch")
+	SetColorIf(qos.PerformanceLimitedBy.Name == "workload", "Green", "Yellow")
+	WriteAtS(COL1, 5, qos.PerformanceLimitedBy.Name)
+	SetColorIf(qos.BatchPerformanceLimitedBy.Name == "workload", "Green", "Yellow")
+	WriteAtS(COL1, 6, qos.BatchPerformanceLimitedBy.Name)
+	SetColor("Gray")
+	WriteAtS(COL1+34, 5, trimMax(qos.PerformanceLimitedBy.Description, ScreenWidth-COL1-35))
+	WriteAtS(COL1+34, 6, trimMax(qos.BatchPerformanceLimitedBy.Description, ScreenWidth-COL1-35))
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 8, "Worst")
+	WriteAtS(COL0, 9, "Limiting")
+	WriteAtS(COL1, 7, "Storage queue")
+	WriteAtS(COL1+16, 7, "Data lag (s)")
+	WriteAtS(COL1+32, 7, "Durability lag (s)")
+	WriteAtS(COL1+54, 7, "Log queue")
+	WriteAtS(COL1+68, 7, "Throttled tags")
+
+	SetColor(MapQueueSizeToColor(float64(qos.WorstQueueBytesStorageServer)))
+	WriteAt(COL1, 8, "%13s", FriendlyBytes(qos.WorstQueueBytesStorageServer))
+	SetColor(MapQueueSizeToColor(float64(qos.LimitingQueueBytesStorageServer)))
+	WriteAt(COL1, 9, "%13s", FriendlyBytes(qos.LimitingQueueBytesStorageServer))
+	SetColor(MapDataLagToColor(qos.WorstDataLagStorageServer.Seconds))
+	WriteAt(COL1+16, 8, "%12.2f", qos.WorstDataLagStorageServer.Seconds)
+	SetColor(MapDataLagToColor(qos.LimitingDataLagStorageServer.Seconds))
+	WriteAt(COL1+16, 9, "%12.2f", qos.LimitingDataLagStorageServer.Seconds)
+	SetColor(MapDurLagToColor(qos.WorstDurabilityLagStorageServer.Seconds))
+	WriteAt(COL1+32, 8, "%18.2f", qos.WorstDurabilityLagStorageServer.Seconds)
+	SetColor(MapDurLagToColor(qos.LimitingDurabilityLagStorageServer.Seconds))
+	WriteAt(COL1+32, 9, "%18.2f", qos.LimitingDurabilityLagStorageServer.Seconds)
+	SetColor(MapQueueSizeToColor(float64(qos.WorstQueueBytesLogServer)))
+	WriteAt(COL1+54, 8, "%9s", FriendlyBytes(qos.WorstQueueBytesLogServer))
+	tags := &qos.ThrottledTags
+	SetColorIf(tags.Auto.Count+tags.Manual.Count > 0, "Yellow", "Gray")
+	WriteAt(COL1+68, 8, "auto %d (busy read %d, busy write %d)", tags.Auto.Count, tags.Auto.BusyRead, tags.Auto.BusyWrite)
+	WriteAt(COL1+68, 9, "manual %d", tags.Manual.Count)
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 11, "Elapsed")
+	WriteAtS(COL1, 11, "Released / Limit (tps)")
+	WriteAtS(COL2, 11, "Batch released / Limit (tps)")
+
+	y := 13 + len(History) - 1
+	for _, metric := range History {
+		if y < ScreenHeight-1 {
+			SetColor("DarkGray")
+			WriteAt(COL0, y, "%9s | %*s | %*s |", TimeSpanInSecondsWithRounding(metric.LocalTime.Seconds()), COL2-COL1-3, "", COL3-COL2-3, "")
+
+			if metric.Available {
+				showRateBar(COL1, y, metric.ReleasedTps, metric.TpsLimit)
+				showRateBar(COL2, y, metric.BatchReleasedTps, metric.BatchTpsLimit)
+			} else {
+				SetColor("DarkRed")
+				WriteAt(COL1, y, "%8s", "x")
+				WriteAt(COL2, y, "%8s", "x")
+			}
+		}
+		y--
+	}
+}
+
+// showRateBar displays a released rate and its limit, with a bar of the share of the limit in use.
+func showRateBar(x, y int, released, limit float64) {
+	ratio := 0.0
+	if limit > 0 {
+		ratio = released / limit
+	}
+	color := "Green"
+	if ratio >= 0.9 {
+		color = "Red"
+	} else if ratio >= 0.5 {
+		color = "Yellow"
+	}
+
+	SetColor(FrequencyColor(released))
+	WriteAt(x, y, "%8.0f", released)
+	SetColor("Gray")
+	WriteAt(x+9, y, "%9s", FriendlyRate(limit))
+	SetColor(color)
+	WriteAtS(x+19, y, strings.Repeat("|", Bar(ratio, 1, MAX_RW_WIDTH)))
+}
+
+// FriendlyRate formats a rate with a metric suffix, ratekeeper limits being huge when it does not throttle.
+func FriendlyRate(x float64) string {
+	switch {
+	case x >= 1e12:
+		return "unlimited"
+	case x >= 1e9:
+		return fmt.Sprintf("%.1fG", x/1e9)
+	case x >= 1e6:
+		return fmt.Sprintf("%.1fM", x/1e6)
+	case x >= 1e4:
+		return fmt.Sprintf("%.1fk", x/1e3)
+	default:
+		return fmt.Sprintf("%.0f", x)
+	}
+}
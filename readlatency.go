@@ -0,0 +1,164 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// readLatencyBuckets are the upper bounds, in seconds, of the distribution of the p99 of storage servers.
+var readLatencyBuckets = []float64{0.001, 0.002, 0.005, 0.01, 0.025, 0.05, 0.1}
+
+// StorageLatency is the read latency distribution of a storage server.
+type StorageLatency struct {
+	Address string
+	Id      string
+	FdbLatencyStatistics
+}
+
+// StorageReadLatencies returns the read latencies of every storage server, slowest p99 first.
+func StorageReadLatencies(status *FdbStatus) []StorageLatency {
+	var latencies []StorageLatency
+	for _, process := range status.Cluster.Processes {
+		for _, role := range process.Roles {
+			if role.Role == StorageRoleMetrics {
+				latencies = append(latencies, StorageLatency{process.Address, role.Id, role.ReadLatencyStatistics})
+			}
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		if latencies[i].P99 != latencies[j].P99 {
+			return latencies[i].P99 > latencies[j].P99
+		}
+		return latencies[i].Address < latencies[j].Address
+	})
+	return latencies
+}
+
+// ReadP99 returns the median and the worst p99 read latency of the storage servers.
+func ReadP99(latencies []StorageLatency) (median, worst float64) {
+	if len(latencies) == 0 {
+		return 0, 0
+	}
+	return latencies[len(latencies)/2].P99, latencies[0].P99
+}
+
+// ShowReadLatencyScreen displays the storage servers slowest p99 read latency first,
+// the distribution of their p99 and the History of the median and worst p99.
+func ShowReadLatencyScreen(status FdbStatus) {
+	const (
+		COL0 = 1
+		COL1 = COL0 + 24
+		COL2 = COL1 + 18
+
+		HISTORY_ROWS = 12
+	)
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+	latencies := StorageReadLatencies(&status)
+	median, worst := ReadP99(latencies)
+
+	for y := 5; y <= 7; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 5, "Storage servers")
+	WriteAtS(COL0, 6, "p99 (ms)")
+	WriteAtS(COL0, 7, "p99 distribution")
+	SetColor("White")
+	WriteAt(COL1, 5, "%d", len(latencies))
+	SetColor(ReadLatencyColor(median))
+	WriteAt(COL1, 6, "median %.3f", median*1000)
+	SetColor(ReadLatencyColor(worst))
+	WriteAt(COL2, 6, "worst %.3f", worst*1000)
+	if len(latencies) > 0 {
+		SetColor("Gray")
+		WriteAt(COL2+20, 6, "%s %s", latencies[0].Address, latencies[0].Id)
+	}
+
+	counts := make([]int, len(readLatencyBuckets)+1)
+	for _, l := range latencies {
+		i := sort.SearchFloat64s(readLatencyBuckets, l.P99)
+		counts[i]++
+	}
+	x := COL1
+	for i, count := range counts {
+		label := "more"
+		if i < len(readLatencyBuckets) {
+			label = "<" + strconv.FormatFloat(readLatencyBuckets[i]*1000, 'g', -1, 64) + "ms"
+		}
+		SetColor("DarkGray")
+		WriteAtS(x, 7, label)
+		SetColorIf(count > 0, "White", "DarkGray")
+		WriteAt(x+len(label)+1, 7, "%-4d", count)
+		x += len(label) + 6
+	}
+
+	SetColor("DarkCyan")
+	WriteAtS(COL0, 9, "Address")
+	WriteAtS(COL1, 9, "Id")
+	WriteAt(COL2, 9, "%8s %8s %8s %8s %8s %8s %8s %8s %10s", "min", "median", "p90", "p95", "p99", "p99.9", "max", "mean", "count")
+
+	rows := ScreenHeight - 1 - 10 - HISTORY_ROWS - 3
+	y := 10
+	for i, l := range latencies {
+		if i >= rows {
+			break
+		}
+		WriteAtS(0, y, emptyLine)
+		SetColor("Gray")
+		WriteAtS(COL0, y, trimMax(l.Address, 23))
+		WriteAtS(COL1, y, trimMax(l.Id, 17))
+		x := COL2
+		for _, v := range []float64{l.Min, l.Median, l.P90, l.P95, l.P99, l.P999, l.Max, l.Mean} {
+			SetColor(ReadLatencyColor(v))
+			WriteAt(x, y, "%8.3f", v*1000)
+			x += 9
+		}
+		SetColor("Gray")
+		WriteAt(x, y, "%10d", l.Count)
+		y++
+	}
+
+	y++
+	SetColor("DarkCyan")
+	WriteAtS(COL0, y, "Elapsed")
+	WriteAtS(COL0+12, y, "Median p99 (ms)")
+	WriteAtS(COL0+12+MAX_RW_WIDTH+12, y, "Worst p99 (ms)")
+
+	maxWorst := GetMax(History, func(m HistoryMetric) float64 { return m.ReadP99Worst })
+	scale := GetMaxScale(maxWorst)
+	y++
+	start := len(History) - (ScreenHeight - 1 - y)
+	if start < 0 {
+		start = 0
+	}
+	for i := len(History) - 1; i >= start && y < ScreenHeight-1; i-- {
+		metric := &History[i]
+		SetColor("DarkGray")
+		WriteAt(COL0, y, "%9s | %8s %40s | %8s %40s |", TimeSpanInSecondsWithRounding(metric.LocalTime.Seconds()), "", "", "", "")
+		if metric.Available {
+			SetColor(ReadLatencyColor(metric.ReadP99Median))
+			WriteAt(COL0+12, y, "%8.3f", metric.ReadP99Median*1000)
+			WriteAtS(COL0+21, y, strings.Repeat("|", Bar(metric.ReadP99Median, scale, MAX_RW_WIDTH)))
+			SetColor(ReadLatencyColor(metric.ReadP99Worst))
+			WriteAt(COL0+12+MAX_RW_WIDTH+12, y, "%8.3f", metric.ReadP99Worst*1000)
+			WriteAtS(COL0+21+MAX_RW_WIDTH+12, y, strings.Repeat("|", Bar(metric.ReadP99Worst, scale, MAX_RW_WIDTH)))
+		} else {
+			SetColor("DarkRed")
+			WriteAt(COL0+12, y, "%8s", "x")
+			WriteAt(COL0+12+MAX_RW_WIDTH+12, y, "%8s", "x")
+		}
+		y++
+	}
+	for ; y < ScreenHeight-1; y++ {
+		WriteAtS(0, y, emptyLine)
+	}
+}
+
+// ReadLatencyColor is the color of a read latency, reads being expected ten times faster than commits.
+func ReadLatencyColor(seconds float64) string {
+	return LatencyColor(seconds * 10)
+}
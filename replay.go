@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	minReplaySpeed = 1.0 / 16
+	maxReplaySpeed = 64
+)
+
+// ReplayEvent replaces the current status and History, after a seek in a replayed session.
+type ReplayEvent struct {
+	when    time.Time
+	cluster *Cluster
+	events  []*StatusEvent
+}
+
+func (e *ReplayEvent) When() time.Time {
+	return e.when
+}
+
+// Player feeds the records of a session to the event loop, at the pace they were recorded.
+type Player struct {
+	mu      sync.Mutex
+	cluster *Cluster
+	records []SessionRecord
+	pos     int
+	paused  bool
+	speed   float64
+	gen     int
+	wake    chan struct{}
+	post    func(ev tcell.Event) error
+}
+
+func NewPlayer(cluster *Cluster, records []SessionRecord, post func(ev tcell.Event) error) *Player {
+	return &Player{
+		cluster: cluster,
+		records: records,
+		speed:   1,
+		wake:    make(chan struct{}, 1),
+		post:    post,
+	}
+}
+
+// Run plays the session, it never returns.
+func (p *Player) Run() {
+	p.Seek(0)
+	for {
+		p.mu.Lock()
+		gen := p.gen
+		var timer <-chan time.Time
+		if !p.paused && p.pos+1 < len(p.records) {
+			delay := p.records[p.pos+1].When.Sub(p.records[p.pos].When)
+			timer = time.After(time.Duration(float64(delay) / p.speed))
+		}
+		p.mu.Unlock()
+
+		select {
+		case <-timer:
+			p.mu.Lock()
+			if p.gen == gen {
+				p.pos++
+				p.postCurrent()
+			}
+			p.mu.Unlock()
+		case <-p.wake:
+		}
+	}
+}
+
+// changed restarts the timer of Run, must be called with the lock held.
+func (p *Player) changed() {
+	p.gen++
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Player) decode(record *SessionRecord) *StatusEvent {
+	snapshot := record.Snapshot()
+	status, err := snapshot.Decode()
+	return &StatusEvent{when: record.When, cluster: p.cluster, status: status, err: err}
+}
+
+// postCurrent posts the record at pos, counting it as dropped if the event queue is full:
+// the event loop also calls the Player, so it cannot wait for the queue.
+func (p *Player) postCurrent() {
+	if err := p.post(p.decode(&p.records[p.pos])); err != nil {
+		p.cluster.Dropped.Add(1)
+	}
+}
+
+// Seek moves to the given record, and rebuilds the History that was on screen at that time.
+func (p *Player) Seek(pos int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seek(pos)
+}
+
+func (p *Player) seek(pos int) {
+	if pos < 0 {
+		pos = 0
+	} else if pos >= len(p.records) {
+		pos = len(p.records) - 1
+	}
+	p.pos = pos
+
+	first := pos - maxHistory + 1
+	if first < 0 {
+		first = 0
+	}
+	ev := &ReplayEvent{when: time.Now(), cluster: p.cluster}
+	for i := first; i <= pos; i++ {
+		ev.events = append(ev.events, p.decode(&p.records[i]))
+	}
+	if err := p.post(ev); err != nil {
+		p.cluster.Dropped.Add(1)
+	}
+	p.changed()
+}
+
+// Step pauses the playback and moves forward or backward by n records.
+func (p *Player) Step(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = true
+	if n == 1 && p.pos+1 < len(p.records) {
+		p.pos++
+		p.postCurrent()
+		p.changed()
+	} else {
+		p.seek(p.pos + n)
+	}
+}
+
+// SeekTime moves to the first record taken at or after t.
+func (p *Player) SeekTime(t time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seek(sort.Search(len(p.records), func(i int) bool {
+		return !p.records[i].When.Before(t)
+	}))
+}
+
+func (p *Player) TogglePause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = !p.paused
+	p.changed()
+}
+
+// SetSpeed multiplies the playback speed by factor.
+func (p *Player) SetSpeed(factor float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.speed *= factor
+	if p.speed < minReplaySpeed {
+		p.speed = minReplaySpeed
+	} else if p.speed > maxReplaySpeed {
+		p.speed = maxReplaySpeed
+	}
+	p.changed()
+}
+
+// Current returns the record on screen.
+func (p *Player) Current() SessionRecord {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.records[p.pos]
+}
+
+// ParseReplayTime parses the target of a jump, either an absolute time in UTC
+// ("2006-01-02 15:04:05", "15:04:05", "15:04") or a duration relative to now ("+30s", "-5m").
+func ParseReplayTime(text string, now time.Time) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "+") || strings.HasPrefix(text, "-") {
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "invalid offset")
+		}
+		return now.Add(d), nil
+	}
+
+	if t, err := time.Parse("2006-01-02 15:04:05", text); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, text); err == nil {
+			y, m, d := now.UTC().Date()
+			return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, errors.Errorf("invalid time %q", text)
+}
+
+// HandleReplayKey processes the playback keys, and returns false for any other key.
+func HandleReplayKey(player *Player, ev *tcell.EventKey) bool {
+	switch {
+	case ev.Rune() == ' ':
+		player.TogglePause()
+	case ev.Key() == tcell.KeyRight || ev.Rune() == '.':
+		player.Step(1)
+	case ev.Key() == tcell.KeyLeft || ev.Rune() == ',':
+		player.Step(-1)
+	case ev.Rune() == '+':
+		player.SetSpeed(2)
+	case ev.Rune() == '-':
+		player.SetSpeed(0.5)
+	case ev.Rune() == 'j':
+		current := player.Current().When
+		OpenPrompt("Jump to (UTC hh:mm:ss, yyyy-mm-dd hh:mm:ss or +/-duration):", "", func(text string) {
+			t, err := ParseReplayTime(text, current)
+			if err != nil {
+				Notify(err.Error())
+				return
+			}
+			player.SeekTime(t)
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// ShowReplayBar displays the playback state on the right of the bottom bar.
+func ShowReplayBar(player *Player) {
+	player.mu.Lock()
+	state := fmt.Sprintf("x%g", player.speed)
+	if player.paused {
+		state = "PAUSED"
+	} else if player.pos+1 >= len(player.records) {
+		state = "END"
+	}
+	text := fmt.Sprintf(" REPLAY %s %s  %d/%d ", state, player.records[player.pos].When.UTC().Format("2006-01-02 15:04:05"), player.pos+1, len(player.records))
+	player.mu.Unlock()
+
+	ScreenWidth, ScreenHeight := screen.Size()
+	SetBackground("DarkMagenta")
+	SetColor("White")
+	WriteAtS(ScreenWidth-len(text), ScreenHeight-1, text)
+	SetBackground("Black")
+}
@@ -10,7 +10,7 @@ import (
 
 var debugLayout = true
 
-func ShowRolesScreen(status FdbStatus) {
+func ShowRolesScreen(status FdbStatus, processHistory map[string][]ProcessSample, roleHistory map[string][]RoleSample) {
 	const (
 		CPU_BARSZ       = 10
 		HDD_BARSZ       = 10
@@ -30,12 +30,12 @@ func ShowRolesScreen(status FdbStatus) {
 		COL_DATAVERSION = COL_STORAGE + LEN_STORAGE + BAR
 		LEN_DATAVERSION = 14
 		COL_KVSTORE     = COL_DATAVERSION + LEN_DATAVERSION + BAR
+		COL_TREND       = COL_KVSTORE + 8 + BAR
+		TREND_WIDTH     = 12
 	)
 
 	var y = 5
 
-	LastProcessYMax = 0
-
 	// Debug layout
 	if debugLayout {
 		SetColor("DarkGray")
@@ -55,7 +55,7 @@ func ShowRolesScreen(status FdbStatus) {
 		return
 	}
 
-	ScreenWidth, ScreenHeight := screen.Size()
+	ScreenWidth, _ := screen.Size()
 	emptyLine := strings.Repeat(" ", ScreenWidth)
 
 	type Role struct {
@@ -64,9 +64,14 @@ func ShowRolesScreen(status FdbStatus) {
 		MachineId string
 	}
 	byRoles := make(map[string][]Role)
+	shown, total := 0, 0
 	for _, process := range status.Cluster.Processes {
 		for _, role := range process.Roles {
-			byRoles[role.Role] = append(byRoles[role.Role], Role{Process: process, Role: role, MachineId: process.MachineId})
+			total++
+			if processFilter.Matches(&process, &role) {
+				byRoles[role.Role] = append(byRoles[role.Role], Role{Process: process, Role: role, MachineId: process.MachineId})
+				shown++
+			}
 		}
 	}
 
@@ -81,9 +86,32 @@ func ShowRolesScreen(status FdbStatus) {
 		}
 		return max
 	}
-	for _, roleId := range []string{"log", "storage", "proxy", "commit_proxy", "grv_proxy", "resolver", "master", "cluster_controller", "data_distributor", "ratekeeper"} {
-		kv := byRoles[roleId]
 
+	// The trend of a role is the data lag of storage servers, the queue of logs and the CPU of the other roles
+	trendName := func(roleId string) string {
+		switch roleId {
+		case StorageRoleMetrics:
+			return "Data lag"
+		case LogRoleMetrics:
+			return "Queue Sz"
+		default:
+			return "CPU"
+		}
+	}
+	trend := func(proc *FdbProcess, role *FdbRole) []float64 {
+		samples := roleHistory[RoleKey(proc, role)]
+		switch role.Role {
+		case StorageRoleMetrics:
+			return RoleSeries(samples, func(s *RoleSample) float64 { return s.DataLag })
+		case LogRoleMetrics:
+			return RoleSeries(samples, func(s *RoleSample) float64 { return float64(s.QueueSize) })
+		default:
+			return ProcessSeries(processHistory[proc.Address], func(s *ProcessSample) float64 { return s.Cpu })
+		}
+	}
+
+	// title and columns draw the two header lines of a role section
+	title := func(y int, roleId string) {
 		hasDisk := roleId == StorageRoleMetrics || roleId == LogRoleMetrics
 
 		SetColor("Cyan")
@@ -99,39 +127,10 @@ func ShowRolesScreen(status FdbStatus) {
 			WriteAtS(COL_DATAVERSION, y, "Data Version")
 			WriteAtS(COL_KVSTORE, y, "KV Store")
 		}
-		y++
-
+		WriteAtS(COL_TREND, y, "Trend")
+	}
+	columns := func(y int, roleId string) {
 		WriteAtS(0, y, emptyLine)
-
-		// WriteAt(COL1, y, "%8.3f", MegaBytes(machine.Network.MegabitsReceived.Hz * 125000))
-		// WriteAt(COL2, y, "%8.3f", MegaBytes(machine.Network.MegabitsSent.Hz * 125000))
-		// WriteAt(COL3, y, "%5.1f", machine.Cpu.LogicalCoreUtilization * 100)
-		// WriteAt(COL4, y, "%5.1f", GigaBytes(machine.Memory.CommittedBytes))
-		// WriteAt(COL5, y, "%5.1f", GigaBytes(machine.Memory.TotalBytes))
-		// SetColor("DarkGray")
-		// WriteAt(COL8, y, "%11s", map)
-
-		// if machine.Cpu.LogicalCoreUtilization >= 0.9 {
-		//     SetColor("Red")
-		// } else {
-		//     SetColor("Green")
-		// }
-		// WriteAt(COL3+7, y, "%-10s", BarGraph(machine.Cpu.LogicalCoreUtilization, 1, CPU_BARSZ, '|', ':')) // 1 = all the (logical) cores
-
-		// memRatio := float64(machine.Memory.CommittedBytes) / float64(machine.Memory.TotalBytes)
-		// if memRatio >= 0.95 {
-		//     SetColor("Red")
-		// } else if memRatio >= 0.79 {
-		//     SetColor("DarkYellow")
-		// } else {
-		//     SetColor("Green")
-		// }
-		// WriteAt(COL5+6, y, "%-10s", BarGraph(machine.Memory.CommittedBytes, machine.Memory.TotalBytes, MEM_BARSZ, '|', ':'))
-
-		// SetColor("DarkGray")
-		// WriteAt(COL6, y, "S: %s; Q: %s, D: %s", FriendlyBytes(storageBytes), FriendlyBytes(queueDiskBytes), FriendlyBytes(int64(blahBytes)))
-
-		maxLogTransaction := int64(0)
 		SetColor("DarkCyan")
 		WriteAtS(COL0, y, "         Address:Port")
 		WriteAtS(COL_NET, y, "   Recv    Sent")
@@ -151,12 +150,43 @@ func ShowRolesScreen(status FdbStatus) {
 			WriteAtS(COL_STORAGE+9, y, "  Input")
 			WriteAtS(COL_STORAGE+17, y, "Durable")
 			WriteAtS(COL_STORAGE+25, y, "    Used")
-			maxLogTransaction = maxDataVersion(kv)
 			WriteAtS(COL_DATAVERSION, y, "        Delta")
 			WriteAtS(COL_KVSTORE, y, "    Used")
 		}
+		WriteAtS(COL_TREND, y, trendName(roleId))
+	}
+
+	scroll := Scrolls[Roles]
+	scroll.Begin(y)
+	row := 0
 
-		y++
+	// The header of a section scrolled past the top stays on the first lines,
+	// until the blank line ending the section reaches the top
+	sticky := ""
+
+	for _, roleId := range []string{"log", "storage", "proxy", "commit_proxy", "grv_proxy", "resolver", "master", "cluster_controller", "data_distributor", "ratekeeper"} {
+		kv := byRoles[roleId]
+		if len(kv) == 0 && processFilter.Text != "" {
+			continue
+		}
+
+		hasDisk := roleId == StorageRoleMetrics || roleId == LogRoleMetrics
+
+		if row < scroll.Offset && scroll.Offset < row+2+len(kv) {
+			sticky = roleId
+		}
+		if y, ok := scroll.Line(row); ok {
+			title(y, roleId)
+		}
+		if y, ok := scroll.Line(row + 1); ok {
+			columns(y, roleId)
+		}
+		row += 2
+
+		maxLogTransaction := int64(0)
+		if roleId == LogRoleMetrics {
+			maxLogTransaction = maxDataVersion(kv)
+		}
 
 		prevHost := ""
 		sort.SliceStable(kv, func(i, j int) bool {
@@ -166,7 +196,11 @@ func ShowRolesScreen(status FdbStatus) {
 			role := item.Role
 			proc := item.Process
 			//machineID := v.MachineId
-			if y < ScreenHeight {
+			selected := scroll.Item(row, proc.Address+"#"+role.Role+role.Id)
+			if y, ok := scroll.Line(row); ok {
+				if selected {
+					SetBackground("DarkBlue")
+				}
 				SetColor("DarkGray")
 				WriteAtS(COL0, y, strings.ReplaceAll(" _______________:_____ | ________ ________ | _____% __________ | ________ | ", "_", " "))
 				if roleId == "storage" {
@@ -296,24 +330,25 @@ func ShowRolesScreen(status FdbStatus) {
 					WriteAt(COL_HDD+7, y, "%-5s", BarGraph(proc.Disk.Busy, 1, HDD_BARSZ, '|', ":", "."))
 				}
 
+				ShowTrend(COL_TREND, y, trend(&proc, &role), TREND_WIDTH)
+
+				if selected {
+					SetBackground("Black")
+				}
 			}
-			y++
+			row++
 		}
-		WriteAt(COL0, y, emptyLine)
-		y++
-	}
-
-	min := func(x, y int) int {
-		if x < y {
-			return x
+		if y, ok := scroll.Line(row); ok {
+			WriteAtS(0, y, emptyLine)
 		}
-		return y
+		row++
 	}
-	y = min(y, ScreenHeight)
-	for ; y < LastProcessYMax; y++ {
-		WriteAt(COL0, y, emptyLine)
+	if sticky != "" && scroll.Page >= 2 {
+		title(scroll.Top, sticky)
+		columns(scroll.Top+1, sticky)
 	}
-	LastProcessYMax = y
+	ShowProcessFilter(shown, total, "roles")
+	scroll.End(row)
 }
 
 func MapDataLagToColor(dataLag float64) string {
@@ -483,8 +518,6 @@ func (r *RoleMap) String() string {
 	return sb.String()
 }
 
-var LastProcessYMax = 0
-
 const (
 	KIBIBYTE = 1024.0
 	MEBIBYTE = 1024.0 * 1024.0
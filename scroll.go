@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// wheelRows is the number of rows scrolled by a turn of the mouse wheel.
+const wheelRows = 3
+
+// Scroll is the vertical position of a screen listing more rows than fit on it.
+// Rows are numbered from the top of the listing, headers drawn above Top stay in place.
+// Some rows can be selected with a cursor, the listing scrolling to keep it on screen.
+type Scroll struct {
+	Offset int    // first row shown
+	Rows   int    // rows of the listing, as of the last repaint
+	Top    int    // screen line of the first row shown
+	Page   int    // rows that fit on the screen
+	Sticky int    // first lines that a sticky header may cover
+	Cursor string // key of the selected row, if any
+
+	items []scrollItem
+}
+
+// scrollItem is a row that can be selected.
+type scrollItem struct {
+	row int
+	key string
+}
+
+// Scrolls are the scroll positions of the scrollable screens.
+var Scrolls = map[DisplayMode]*Scroll{
+	Processes: {},
+	Roles:     {Sticky: 2},
+}
+
+// ScreenScroll returns the scroll position of a screen, or of the process detail shown over it, if it scrolls.
+func ScreenScroll(mode DisplayMode) (*Scroll, bool) {
+	if detailAddress != "" && (mode == Processes || mode == Roles) {
+		return detailScroll, true
+	}
+	s, ok := Scrolls[mode]
+	return s, ok
+}
+
+// Begin starts drawing the listing from the screen line top down to the bottom bar.
+func (s *Scroll) Begin(top int) {
+	_, ScreenHeight := screen.Size()
+	s.Top = top
+	s.Page = ScreenHeight - 1 - top
+	if s.Page < 0 {
+		s.Page = 0
+	}
+	s.items = s.items[:0]
+	s.clamp()
+}
+
+// Item records a row that can be selected, identified by key, and returns true if it is the selected row.
+func (s *Scroll) Item(row int, key string) bool {
+	s.items = append(s.items, scrollItem{row, key})
+	return key == s.Cursor
+}
+
+// ItemAt returns the key of the selectable row at a screen line, if any.
+func (s *Scroll) ItemAt(y int) (string, bool) {
+	if y < s.Top || y >= s.Top+s.Page {
+		return "", false
+	}
+	for _, item := range s.items {
+		if item.row == y-s.Top+s.Offset {
+			return item.key, true
+		}
+	}
+	return "", false
+}
+
+// Line returns the screen line of a row of the listing, and false if the row is not shown.
+func (s *Scroll) Line(row int) (int, bool) {
+	if row < s.Offset || row >= s.Offset+s.Page {
+		return 0, false
+	}
+	return s.Top + row - s.Offset, true
+}
+
+// End records the number of rows of the listing, clears the lines below it
+// and draws the scroll indicator.
+func (s *Scroll) End(rows int) {
+	ScreenWidth, _ := screen.Size()
+	emptyLine := strings.Repeat(" ", ScreenWidth)
+	s.Rows = rows
+
+	for row := rows; row < s.Offset+s.Page; row++ {
+		if y, ok := s.Line(row); ok {
+			WriteAtS(0, y, emptyLine)
+		}
+	}
+
+	const LEN_POSITION = 24
+	SetColor("DarkGray")
+	WriteAt(ScreenWidth-LEN_POSITION-1, 4, "%*s", LEN_POSITION, "")
+	if rows <= s.Page {
+		for i := 0; i < s.Page; i++ {
+			WriteAtS(ScreenWidth-1, s.Top+i, " ")
+		}
+		return
+	}
+
+	last := s.Offset + s.Page
+	if last > rows {
+		last = rows
+	}
+	position := fmt.Sprintf("rows %d-%d of %d", s.Offset+1, last, rows)
+	WriteAt(ScreenWidth-len(position)-2, 4, "%s", position)
+
+	// Scroll bar on the last column, the thumb being proportional to the part shown
+	thumb := s.Page * s.Page / rows
+	if thumb < 1 {
+		thumb = 1
+	}
+	start := s.Offset * (s.Page - thumb) / (rows - s.Page)
+	for i := 0; i < s.Page; i++ {
+		if i >= start && i < start+thumb {
+			SetColor("Gray")
+			WriteAtS(ScreenWidth-1, s.Top+i, "█")
+		} else {
+			SetColor("DarkGray")
+			WriteAtS(ScreenWidth-1, s.Top+i, "│")
+		}
+	}
+}
+
+// ScrollBy moves the listing by n rows, up when n is negative.
+// A cursor scrolled out of the screen moves to the first selectable row shown.
+func (s *Scroll) ScrollBy(n int) {
+	s.Offset += n
+	s.clamp()
+
+	if i := s.cursorIndex(); i >= 0 && !s.visible(s.items[i].row) {
+		for _, item := range s.items {
+			if s.visible(item.row) {
+				s.Cursor = item.key
+				break
+			}
+		}
+	}
+}
+
+// MoveCursor selects the n-th selectable row after the selected one, before it when n is negative,
+// and scrolls to keep it on screen. Without selectable rows, the listing scrolls by n rows.
+func (s *Scroll) MoveCursor(n int) {
+	if len(s.items) == 0 {
+		s.ScrollBy(n)
+		return
+	}
+
+	i := s.cursorIndex()
+	if i < 0 {
+		// The first move selects the first row shown
+		i = 0
+		for i < len(s.items)-1 && !s.visible(s.items[i].row) {
+			i++
+		}
+	} else {
+		i += n
+	}
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(s.items) {
+		i = len(s.items) - 1
+	}
+
+	s.Cursor = s.items[i].key
+	if row := s.items[i].row; row < s.Offset+s.Sticky {
+		s.Offset = row - s.Sticky
+	} else if row >= s.Offset+s.Page {
+		s.Offset = row - s.Page + 1
+	}
+	s.clamp()
+}
+
+func (s *Scroll) cursorIndex() int {
+	for i, item := range s.items {
+		if item.key == s.Cursor {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Scroll) visible(row int) bool {
+	return row >= s.Offset+s.Sticky && row < s.Offset+s.Page
+}
+
+func (s *Scroll) clamp() {
+	if s.Offset > s.Rows-s.Page {
+		s.Offset = s.Rows - s.Page
+	}
+	if s.Offset < 0 {
+		s.Offset = 0
+	}
+}
+
+// HandleKey processes the scrolling keys, and returns false for any other key.
+func (s *Scroll) HandleKey(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyUp:
+		s.MoveCursor(-1)
+	case tcell.KeyDown:
+		s.MoveCursor(1)
+	case tcell.KeyPgUp:
+		s.ScrollBy(-s.Page)
+	case tcell.KeyPgDn:
+		s.ScrollBy(s.Page)
+	case tcell.KeyHome:
+		s.ScrollBy(-s.Rows)
+	case tcell.KeyEnd:
+		s.ScrollBy(s.Rows)
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleMouse scrolls with the mouse wheel, and returns false for any other mouse event.
+func (s *Scroll) HandleMouse(ev *tcell.EventMouse) bool {
+	switch {
+	case ev.Buttons()&tcell.WheelUp != 0:
+		s.ScrollBy(-wheelRows)
+	case ev.Buttons()&tcell.WheelDown != 0:
+		s.ScrollBy(wheelRows)
+	default:
+		return false
+	}
+	return true
+}
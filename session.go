@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SessionRecord is one polled status of a recorded session.
+// A session file is a gzip stream of json lines, one record per line. Every record is
+// written as a gzip member of its own, so that a killed recording leaves at most one
+// unfinished member at the end of the file, cut before the next recording appends to it.
+type SessionRecord struct {
+	When        time.Time       `json:"when"`
+	ReadVersion int64           `json:"read_version"`
+	Status      json.RawMessage `json:"status"`
+}
+
+// Snapshot returns the record as it was read from its StatusSource.
+func (r *SessionRecord) Snapshot() StatusSnapshot {
+	return StatusSnapshot{When: r.When, ReadVersion: r.ReadVersion, JSON: r.Status}
+}
+
+// ReadSession loads all the records of a session file.
+// A file truncated by an interrupted recording is read up to its last complete record.
+func ReadSession(path string) ([]SessionRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open session file")
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read session file")
+	}
+	defer gz.Close()
+
+	var records []SessionRecord
+	dec := json.NewDecoder(gz)
+	for {
+		var record SessionRecord
+		if err := dec.Decode(&record); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return records, nil
+			}
+			return records, errors.Wrapf(err, "cannot decode session record #%d", len(records)+1)
+		}
+		records = append(records, record)
+	}
+}
+
+// SessionSource reads the records of a session in order, as fast as they are polled.
+type SessionSource struct {
+	records []SessionRecord
+	next    int
+}
+
+func NewSessionSource(records []SessionRecord) *SessionSource {
+	return &SessionSource{records: records}
+}
+
+func (s *SessionSource) Next() (StatusSnapshot, error) {
+	if s.next >= len(s.records) {
+		return StatusSnapshot{}, io.EOF
+	}
+	s.next++
+	return s.records[s.next-1].Snapshot(), nil
+}
+
+// SessionWriter appends records to a session file.
+type SessionWriter struct {
+	file *os.File
+	gz   *gzip.Writer
+	enc  *json.Encoder
+}
+
+// OpenSessionWriter opens a session file to append records to it,
+// after cutting the unfinished record left by a killed recording, if any.
+func OpenSessionWriter(path string) (*SessionWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open session file")
+	}
+	end, err := sessionEnd(file)
+	if err == nil {
+		err = file.Truncate(end)
+	}
+	if err == nil {
+		_, err = file.Seek(end, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "cannot append to session file %s", path)
+	}
+	gz := gzip.NewWriter(file)
+	return &SessionWriter{file: file, gz: gz, enc: json.NewEncoder(gz)}, nil
+}
+
+// sessionEnd returns the offset of the end of the last complete gzip member of a session file.
+func sessionEnd(r io.Reader) (int64, error) {
+	cr := &countingReader{r: bufio.NewReader(r)}
+	var end int64
+	gz := new(gzip.Reader)
+	for {
+		err := gz.Reset(cr)
+		if err == nil {
+			gz.Multistream(false)
+			_, err = io.Copy(io.Discard, gz)
+		}
+		switch {
+		case err == io.EOF && cr.n == end:
+			return end, nil
+		case err == io.ErrUnexpectedEOF:
+			// The member was cut by a killed recording
+			return end, nil
+		case err != nil:
+			return end, errors.Wrapf(err, "corrupt session record at offset %d", end)
+		}
+		end = cr.n
+	}
+}
+
+// countingReader counts the bytes read, gzip reading them one by one from an io.ByteReader.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// Write appends a snapshot to the session as a complete gzip member,
+// so that the file stays readable if fdbtop is killed.
+func (w *SessionWriter) Write(snapshot StatusSnapshot) error {
+	record := SessionRecord{When: snapshot.When, ReadVersion: snapshot.ReadVersion, Status: snapshot.JSON}
+	w.gz.Reset(w.file)
+	if err := w.enc.Encode(&record); err != nil {
+		return errors.Wrap(err, "cannot encode session record")
+	}
+	if err := w.gz.Close(); err != nil {
+		return errors.Wrap(err, "cannot write session record")
+	}
+	return nil
+}
+
+func (w *SessionWriter) Close() error {
+	return w.file.Close()
+}
+
+// RecordingSource writes every snapshot read from a StatusSource to a session.
+// Recording stops at the first write error, reported as the warning of the snapshot it happened on.
+type RecordingSource struct {
+	source  StatusSource
+	writer  *SessionWriter
+	stopped bool
+}
+
+func NewRecordingSource(source StatusSource, writer *SessionWriter) *RecordingSource {
+	return &RecordingSource{source: source, writer: writer}
+}
+
+func (s *RecordingSource) Next() (StatusSnapshot, error) {
+	snapshot, err := s.source.Next()
+	if err == nil && len(snapshot.JSON) > 0 && !s.stopped {
+		if err := s.writer.Write(snapshot); err != nil {
+			s.stopped = true
+			snapshot.Warning = errors.Wrap(err, "recording stopped")
+		}
+	}
+	return snapshot, err
+}
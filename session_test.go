@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeSession(t *testing.T, path string, from, to int) {
+	t.Helper()
+	w, err := OpenSessionWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := from; i < to; i++ {
+		snapshot := StatusSnapshot{When: time.Unix(int64(i), 0), ReadVersion: int64(i), JSON: []byte(`{"cluster":{}}`)}
+		if err := w.Write(snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionAppendAfterKilledRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.fdbtop")
+	writeSession(t, path, 1, 4)
+
+	// A recording killed while writing its third record leaves it unfinished
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-20); err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadSession(path)
+	if err != nil || len(records) != 2 {
+		t.Fatalf("got %d records and error %v after the killed recording, expected 2", len(records), err)
+	}
+
+	writeSession(t, path, 4, 6)
+	records, err = ReadSession(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []int64
+	for _, r := range records {
+		versions = append(versions, r.ReadVersion)
+	}
+	if len(versions) != 4 || versions[0] != 1 || versions[1] != 2 || versions[2] != 4 || versions[3] != 5 {
+		t.Errorf("got read versions %v, expected [1 2 4 5]", versions)
+	}
+}
+
+func TestSessionAppendToOtherFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status.json")
+	if err := os.WriteFile(path, []byte(`{"cluster":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenSessionWriter(path); err == nil {
+		t.Error("expected an error appending to a file that is not a session")
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"cluster":{}}` {
+		t.Errorf("the file was changed to %q", data)
+	}
+}
+
+func TestRecordingSourceWriteError(t *testing.T) {
+	w, err := OpenSessionWriter(filepath.Join(t.TempDir(), "session.fdbtop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	records := []SessionRecord{{ReadVersion: 1, Status: []byte(`{}`)}, {ReadVersion: 2, Status: []byte(`{}`)}}
+	source := NewRecordingSource(NewSessionSource(records), w)
+	for i, expectWarning := range []bool{true, false} {
+		snapshot, err := source.Next()
+		if err != nil {
+			t.Fatalf("poll #%d failed: %v", i+1, err)
+		}
+		if (snapshot.Warning != nil) != expectWarning {
+			t.Errorf("poll #%d: got warning %v", i+1, snapshot.Warning)
+		}
+	}
+}
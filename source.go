@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/apple/foundationdb/bindings/go/src/fdb"
+	"github.com/pkg/errors"
+)
+
+// A special key to read the status json. https://apple.github.io/foundationdb/mr-status.html
+var jsonKey = append([]byte{255, 255}, []byte("/status/json")...)
+
+// StatusSnapshot is a raw status json document, as read from a StatusSource.
+type StatusSnapshot struct {
+	When        time.Time
+	ReadVersion int64
+	JSON        []byte
+
+	// Warning is a problem of the source that did not fail the read, like a recording that stopped.
+	Warning error
+}
+
+// Decode parses the status json of the snapshot.
+func (s *StatusSnapshot) Decode() (FdbStatus, error) {
+	var status FdbStatus
+	if err := json.Unmarshal(s.JSON, &status); err != nil {
+		return FdbStatus{}, errors.Wrap(err, "cannot decode json")
+	}
+	status.ReadVersion = s.ReadVersion
+	if status.ReadVersion == 0 {
+		// Dumps saved with `fdbcli --exec "status json"` carry no read version,
+		// use the most recent version known by the transaction logs instead.
+		for _, p := range status.Cluster.Processes {
+			for _, role := range p.Roles {
+				if role.Role == LogRoleMetrics && role.DataVersion > status.ReadVersion {
+					status.ReadVersion = role.DataVersion
+				}
+			}
+		}
+	}
+	return status, nil
+}
+
+// StatusSource produces the status json documents displayed by fdbtop.
+// Next returns io.EOF once the source has nothing more to offer.
+type StatusSource interface {
+	Next() (StatusSnapshot, error)
+}
+
+// DatabaseSource reads the status json from a live cluster.
+type DatabaseSource struct {
+	db fdb.Database
+}
+
+func NewDatabaseSource(db fdb.Database) *DatabaseSource {
+	return &DatabaseSource{db: db}
+}
+
+func (s *DatabaseSource) Next() (StatusSnapshot, error) {
+	ret, err := s.db.ReadTransact(func(tr fdb.ReadTransaction) (interface{}, error) {
+		rv := tr.GetReadVersion().MustGet()
+		json := tr.Get(fdb.Key(jsonKey)).MustGet()
+		return &StatusSnapshot{When: time.Now(), ReadVersion: rv, JSON: json}, nil
+	})
+	if err != nil {
+		return StatusSnapshot{}, errors.Wrap(err, "cannot get status")
+	}
+	return *ret.(*StatusSnapshot), nil
+}
+
+// FileSource reads the status json from a file, every time it is polled,
+// so that a file rewritten by another tool is picked up.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (s *FileSource) Next() (StatusSnapshot, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return StatusSnapshot{}, errors.Wrap(err, "cannot read status file")
+	}
+	return StatusSnapshot{When: time.Now(), JSON: data}, nil
+}
+
+// DirectorySource plays back the *.json snapshots of a directory, one per poll,
+// in the lexical order of their names.
+type DirectorySource struct {
+	paths []string
+	next  int
+}
+
+func NewDirectorySource(dir string) (*DirectorySource, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list snapshots")
+	}
+	if len(paths) == 0 {
+		return nil, errors.Errorf("no *.json snapshot in %s", dir)
+	}
+	sort.Strings(paths)
+	return &DirectorySource{paths: paths}, nil
+}
+
+func (s *DirectorySource) Next() (StatusSnapshot, error) {
+	if s.next >= len(s.paths) {
+		return StatusSnapshot{}, io.EOF
+	}
+	path := s.paths[s.next]
+	s.next++
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return StatusSnapshot{}, errors.Wrap(err, "cannot read snapshot")
+	}
+	return StatusSnapshot{When: time.Now(), JSON: data}, nil
+}
+
+// ReaderSource reads a stream of concatenated status json documents,
+// typically piped through stdin.
+type ReaderSource struct {
+	decoder *json.Decoder
+}
+
+func NewReaderSource(r io.Reader) *ReaderSource {
+	return &ReaderSource{decoder: json.NewDecoder(bufio.NewReader(r))}
+}
+
+func (s *ReaderSource) Next() (StatusSnapshot, error) {
+	var doc json.RawMessage
+	if err := s.decoder.Decode(&doc); err != nil {
+		if err == io.EOF {
+			return StatusSnapshot{}, io.EOF
+		}
+		return StatusSnapshot{}, errors.Wrap(err, "cannot read status stream")
+	}
+	return StatusSnapshot{When: time.Now(), JSON: doc}, nil
+}
+
+// Poll reads a new status from the source every interval and hands it to fn,
+// with the error of the poll if it failed, until the source is exhausted or fn returns false.
+func Poll(source StatusSource, interval func() time.Duration, fn func(*StatusEvent) bool) {
+	for {
+		snapshot, err := source.Next()
+		if err == io.EOF {
+			return
+		}
+		ev := &StatusEvent{when: time.Now(), err: err, warning: snapshot.Warning}
+		if err == nil {
+			ev.when = snapshot.When
+			ev.status, ev.err = snapshot.Decode()
+		}
+
+		if !fn(ev) {
+			return
+		}
+
+		time.Sleep(interval())
+	}
+}
@@ -1,38 +1,5 @@
 package main
 
-import (
-	"encoding/json"
-	"github.com/apple/foundationdb/bindings/go/src/fdb"
-	"github.com/pkg/errors"
-)
-
-// A special key to read the status json. https://apple.github.io/foundationdb/mr-status.html
-var jsonKey = append([]byte{255, 255}, []byte("/status/json")...)
-
-func getMetrics(db fdb.Database) (FdbStatus, error) {
-	type ret struct {
-		json []byte
-		rv   int64
-	}
-	rv, err := db.ReadTransact(func(tr fdb.ReadTransaction) (interface{}, error) {
-		rv := tr.GetReadVersion().MustGet()
-		json := tr.Get(fdb.Key(jsonKey)).MustGet()
-		return &ret{json: json, rv: rv}, nil
-	})
-	if err != nil {
-		return FdbStatus{}, errors.Wrap(err, "cannot get status")
-	}
-
-	r := rv.(*ret)
-	var status FdbStatus
-	if err := json.Unmarshal(r.json, &status); err != nil {
-		return FdbStatus{}, errors.Wrap(err, "cannot decode json")
-	}
-	status.ReadVersion = r.rv
-
-	return status, nil
-}
-
 const (
 	StorageRoleMetrics = "storage"
 	LogRoleMetrics     = "log"
@@ -51,6 +18,7 @@ type FdbMachine struct {
 	Excluded bool `json:"excluded"`
 	Locality struct {
 		DataHall  string `json:"data_hall"`
+		Dcid      string `json:"dcid"`
 		Machineid string `json:"machineid"`
 		Processid string `json:"processid"`
 		Zoneid    string `json:"zoneid"`
@@ -145,20 +113,9 @@ type FdbRole struct {
 		Hz        float64 `json:"hz"`
 		Roughness float64 `json:"roughness"`
 	} `json:"mutations,omitempty"`
-	QueryQueueMax         int64 `json:"query_queue_max,omitempty"`
-	ReadLatencyStatistics struct {
-		Count  int64   `json:"count"`
-		Max    float64 `json:"max"`
-		Mean   float64 `json:"mean"`
-		Median float64 `json:"median"`
-		Min    float64 `json:"min"`
-		P25    float64 `json:"p25"`
-		P90    float64 `json:"p90"`
-		P95    float64 `json:"p95"`
-		P99    float64 `json:"p99"`
-		P999   float64 `json:"p99.9"`
-	} `json:"read_latency_statistics,omitempty"`
-	StorageMetadata struct {
+	QueryQueueMax         int64                `json:"query_queue_max,omitempty"`
+	ReadLatencyStatistics FdbLatencyStatistics `json:"read_latency_statistics,omitempty"`
+	StorageMetadata       struct {
 		CreatedTimeDatetime  string  `json:"created_time_datetime"`
 		CreatedTimeTimestamp float64 `json:"created_time_timestamp"`
 	} `json:"storage_metadata,omitempty"`
@@ -174,6 +131,35 @@ type FdbRole struct {
 	QueueDiskUsedBytes      int64 `json:"queue_disk_used_bytes"`
 }
 
+// FdbLatencyStatistics is the distribution of a latency, in seconds.
+type FdbLatencyStatistics struct {
+	Count  int64   `json:"count"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	Min    float64 `json:"min"`
+	P25    float64 `json:"p25"`
+	P90    float64 `json:"p90"`
+	P95    float64 `json:"p95"`
+	P99    float64 `json:"p99"`
+	P999   float64 `json:"p99.9"`
+}
+
+// QueueBytes returns the bytes received but not yet durable, for storage and log roles.
+func (r *FdbRole) QueueBytes() int64 {
+	return r.InputBytes.Counter - r.DurableBytes.Counter
+}
+
+// FdbMessage is a message of the cluster, of the client or of a process.
+// Time and Type are only set on process messages, and Severity when the status reports one.
+type FdbMessage struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Time        float64 `json:"time,omitempty"`
+	Type        string  `json:"type,omitempty"`
+	Severity    int64   `json:"severity,omitempty"`
+}
+
 type FdbProcess struct {
 	Address     string `json:"address"`
 	ClassSource string `json:"class_source"`
@@ -201,6 +187,7 @@ type FdbProcess struct {
 	FaultDomain string `json:"fault_domain"`
 	Locality    struct {
 		DataHall  string `json:"data_hall"`
+		Dcid      string `json:"dcid"`
 		Machineid string `json:"machineid"`
 		Processid string `json:"processid"`
 		Zoneid    string `json:"zoneid"`
@@ -213,7 +200,7 @@ type FdbProcess struct {
 		UnusedAllocatedMemory int64 `json:"unused_allocated_memory"`
 		UsedBytes             int64 `json:"used_bytes"`
 	} `json:"memory"`
-	Messages []interface{} `json:"messages"`
+	Messages []FdbMessage `json:"messages"`
 	Network  struct {
 		ConnectionErrors struct {
 			Hz float64 `json:"hz"`
@@ -263,8 +250,8 @@ type FdbStatus struct {
 			Available bool `json:"available"`
 			Healthy   bool `json:"healthy"`
 		} `json:"database_status"`
-		Messages  []interface{} `json:"messages"`
-		Timestamp int64         `json:"timestamp"`
+		Messages  []FdbMessage `json:"messages"`
+		Timestamp int64        `json:"timestamp"`
 	} `json:"client"`
 	Cluster struct {
 		ActivePrimaryDc string `json:"active_primary_dc"`
@@ -397,7 +384,16 @@ type FdbStatus struct {
 				InstancesRunning int64   `json:"instances_running"`
 				LastUpdated      float64 `json:"last_updated"`
 				Paused           bool    `json:"paused"`
-				Tags             struct {
+				Tags             map[string]struct {
+					CurrentContainer            string  `json:"current_container"`
+					CurrentStatus               string  `json:"current_status"`
+					LastRestorableSecondsBehind float64 `json:"last_restorable_seconds_behind"`
+					LastRestorableVersion       int64   `json:"last_restorable_version"`
+					MutationLogBytesWritten     int64   `json:"mutation_log_bytes_written"`
+					MutationStreamId            string  `json:"mutation_stream_id"`
+					RangeBytesWritten           int64   `json:"range_bytes_written"`
+					RunningBackup               bool    `json:"running_backup"`
+					RunningBackupIsRestorable   bool    `json:"running_backup_is_restorable"`
 				} `json:"tags"`
 				TotalWorkers int64 `json:"total_workers"`
 			} `json:"backup"`
@@ -417,7 +413,7 @@ type FdbStatus struct {
 			PossiblyLosingData   bool  `json:"possibly_losing_data"`
 		} `json:"logs"`
 		Machines  map[string]FdbMachine `json:"machines"`
-		Messages  []interface{}         `json:"messages"`
+		Messages  []FdbMessage          `json:"messages"`
 		PageCache struct {
 			LogHitRate     float64 `json:"log_hit_rate"`
 			StorageHitRate float64 `json:"storage_hit_rate"`
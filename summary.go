@@ -0,0 +1,124 @@
+package main
+
+import (
+	"sort"
+)
+
+// ProcessSummary is the digest of a process, as displayed on the Processes screen.
+type ProcessSummary struct {
+	Address         string   `json:"address"`
+	MachineId       string   `json:"machine_id"`
+	Class           string   `json:"class"`
+	Version         string   `json:"version"`
+	Excluded        bool     `json:"excluded"`
+	Roles           []string `json:"roles"`
+	CpuCores        float64  `json:"cpu_cores"`
+	MemoryUsed      int64    `json:"memory_used_bytes"`
+	MemoryLimit     int64    `json:"memory_limit_bytes"`
+	DiskBusy        float64  `json:"disk_busy"`
+	Connections     int64    `json:"connections"`
+	MegabitsRecv    float64  `json:"megabits_received_hz"`
+	MegabitsSent    float64  `json:"megabits_sent_hz"`
+	QueueBytes      int64    `json:"queue_bytes"`
+	QueriedBytesHz  float64  `json:"queried_bytes_hz"`
+	MutationBytesHz float64  `json:"mutation_bytes_hz"`
+	UptimeSeconds   float64  `json:"uptime_seconds"`
+}
+
+// RoleSummary aggregates all the instances of a role in the cluster.
+type RoleSummary struct {
+	Role               string  `json:"role"`
+	Count              int     `json:"count"`
+	QueueBytes         int64   `json:"queue_bytes"`
+	MaxQueueBytes      int64   `json:"max_queue_bytes"`
+	MaxDataLag         float64 `json:"max_data_lag_seconds"`
+	MaxDurabilityLag   float64 `json:"max_durability_lag_seconds"`
+	InputBytesHz       float64 `json:"input_bytes_hz"`
+	QueriedBytesHz     float64 `json:"queried_bytes_hz"`
+	MutationBytesHz    float64 `json:"mutation_bytes_hz"`
+	StoredBytes        int64   `json:"stored_bytes"`
+	QueueDiskUsedBytes int64   `json:"queue_disk_used_bytes"`
+}
+
+// SummarizeProcesses returns the summary of every process, sorted by address.
+func SummarizeProcesses(status FdbStatus) []ProcessSummary {
+	summaries := make([]ProcessSummary, 0, len(status.Cluster.Processes))
+	for _, proc := range status.Cluster.Processes {
+		s := ProcessSummary{
+			Address:       proc.Address,
+			MachineId:     proc.MachineId,
+			Class:         proc.ClassType,
+			Version:       proc.Version,
+			Excluded:      proc.Excluded,
+			CpuCores:      proc.Cpu.UsageCores,
+			MemoryUsed:    proc.Memory.UsedBytes - proc.Memory.UnusedAllocatedMemory,
+			MemoryLimit:   proc.Memory.LimitBytes,
+			DiskBusy:      proc.Disk.Busy,
+			Connections:   proc.Network.CurrentConnections,
+			MegabitsRecv:  proc.Network.MegabitsReceived.Hz,
+			MegabitsSent:  proc.Network.MegabitsSent.Hz,
+			UptimeSeconds: proc.UptimeSeconds,
+		}
+		for _, role := range proc.Roles {
+			s.Roles = append(s.Roles, role.Role)
+			switch role.Role {
+			case StorageRoleMetrics:
+				s.QueueBytes += role.QueueBytes()
+				s.QueriedBytesHz += role.BytesQueried.Hz
+				s.MutationBytesHz += role.MutationBytes.Hz
+			case LogRoleMetrics:
+				s.QueueBytes += role.QueueBytes()
+			}
+		}
+		summaries = append(summaries, s)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Address < summaries[j].Address
+	})
+	return summaries
+}
+
+// SummarizeRoles returns the summary of every role running in the cluster, sorted by name.
+func SummarizeRoles(status FdbStatus) []RoleSummary {
+	byRole := make(map[string]*RoleSummary)
+	for _, proc := range status.Cluster.Processes {
+		for _, role := range proc.Roles {
+			s, ok := byRole[role.Role]
+			if !ok {
+				s = &RoleSummary{Role: role.Role}
+				byRole[role.Role] = s
+			}
+			s.Count++
+			if role.Role != StorageRoleMetrics && role.Role != LogRoleMetrics {
+				continue
+			}
+
+			queue := role.QueueBytes()
+			s.QueueBytes += queue
+			if queue > s.MaxQueueBytes {
+				s.MaxQueueBytes = queue
+			}
+			if role.DataLag.Seconds > s.MaxDataLag {
+				s.MaxDataLag = role.DataLag.Seconds
+			}
+			if role.DurabilityLag.Seconds > s.MaxDurabilityLag {
+				s.MaxDurabilityLag = role.DurabilityLag.Seconds
+			}
+			s.InputBytesHz += role.InputBytes.Hz
+			s.QueriedBytesHz += role.BytesQueried.Hz
+			s.MutationBytesHz += role.MutationBytes.Hz
+			s.StoredBytes += role.StoredBytes
+			s.QueueDiskUsedBytes += role.QueueDiskUsedBytes
+		}
+	}
+
+	summaries := make([]RoleSummary, 0, len(byRole))
+	for _, s := range byRole {
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Role < summaries[j].Role
+	})
+	return summaries
+}
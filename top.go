@@ -1,7 +1,9 @@
 package main
 
 import (
+	"fmt"
 	"math"
+	"strings"
 	"time"
 )
 
@@ -40,7 +42,8 @@ func RepaintTopBar() {
 	WriteAt(TOP_COL4, TOP_ROW2, "Perf.: %20s", "")
 }
 
-func UpdateTopBar(status FdbStatus, current HistoryMetric) {
+// UpdateTopBar displays the values of the status, dimmed when the status is stale.
+func UpdateTopBar(status FdbStatus, current HistoryMetric, stale bool) {
 	const (
 		TOP_COL0 = 1
 		TOP_COL1 = TOP_COL0 + 24
@@ -53,7 +56,12 @@ func UpdateTopBar(status FdbStatus, current HistoryMetric) {
 		TOP_ROW2 = 2
 	)
 
-	SetColor("White")
+	valueColor := "White"
+	if stale {
+		valueColor = "DarkYellow"
+	}
+
+	SetColor(valueColor)
 	WriteAt(TOP_COL0+9, TOP_ROW0, "%8.0f", current.ReadsPerSecond)
 	WriteAt(TOP_COL0+9, TOP_ROW1, "%8.0f", current.WritesPerSecond)
 	WriteAt(TOP_COL0+9, TOP_ROW2, "%8.2f", MegaBytes(int64(current.WrittenBytesPerSecond)))
@@ -68,31 +76,78 @@ func UpdateTopBar(status FdbStatus, current HistoryMetric) {
 
 	format := "02 Jan 06 15:04:05"
 	WriteAt(TOP_COL2+14, TOP_ROW0, "%-19s", serverTime.Format(format))
-	SetColorIf(math.Abs(serverTime.Sub(clientTime).Seconds()) >= 20, "Red", "White")
+	SetColorIf(math.Abs(serverTime.Sub(clientTime).Seconds()) >= 20, "Red", valueColor)
 	WriteAt(TOP_COL2+14, TOP_ROW1, "%-19s", clientTime.Format(format))
-	SetColor("White")
+	SetColor(valueColor)
 	WriteAt(TOP_COL2+14, TOP_ROW2, "%d", current.ReadVersion)
 
-	trimMax := func(s string, max int) string {
-		if len(s) > max {
-			return s[:max]
-		}
-		return s
+	if reachable, total, margin := CoordinatorsQuorum(&status); total > 0 {
+		SetColor(CoordinatorsColor(reachable, total, margin))
+		WriteAt(TOP_COL3+12, TOP_ROW0, "%-10s", fmt.Sprintf("%d/%d [a]", reachable, total))
+		SetColor(valueColor)
+	} else {
+		WriteAt(TOP_COL3+12, TOP_ROW0, "%-10d", status.Cluster.Configuration.CoordinatorsCount)
 	}
-
-	WriteAt(TOP_COL3+12, TOP_ROW0, "%-10d", status.Cluster.Configuration.CoordinatorsCount)
 	WriteAt(TOP_COL3+12, TOP_ROW1, "%-10s", trimMax(status.Cluster.Configuration.StorageEngine, 9))
-	WriteAt(TOP_COL3+12, TOP_ROW2, "%-10s", status.Cluster.Configuration.RedundancyMode)
-
-	//if !status.Client.DatabaseAvailable {
-	if false {
+	if ReducedTolerance(&status) {
 		SetColor("Red")
-		WriteAtS(TOP_COL4+7, TOP_ROW0, "UNAVAILABLE")
-	} else {
-		SetColor("Green")
-		WriteAtS(TOP_COL4+7, TOP_ROW0, "Available  ")
 	}
-	SetColor("White")
+	WriteAt(TOP_COL3+12, TOP_ROW2, "%-10s", status.Cluster.Configuration.RedundancyMode)
+	SetColor(valueColor)
+
+	health := CheckHealth(&status)
+	SetColor(health.Color())
+	WriteAt(TOP_COL4+7, TOP_ROW0, "%-40s", health.Label()+" [!]")
+	SetColor(valueColor)
 	WriteAt(TOP_COL4+7, TOP_ROW1, "%-40s", status.Cluster.Data.State.Name)
 	WriteAt(TOP_COL4+7, TOP_ROW2, "%-40s", status.Cluster.Qos.PerformanceLimitedBy.Name)
 }
+
+// IsHealthIndicator returns true if x, y is on the database state indicator of the top bar.
+func IsHealthIndicator(x, y int) bool {
+	const TOP_COL4 = 1 + 24 + 26 + 36 + 22
+	return y == 0 && x >= TOP_COL4
+}
+
+// ShowStatusLine displays, below the top bar, the last poll error of the cluster
+// and how old the status on screen is, once something went wrong, and its warning.
+func ShowStatusLine(cluster *Cluster, now time.Time) {
+	const STATUS_ROW = 3
+
+	ScreenWidth, _ := screen.Size()
+	WriteAtS(0, STATUS_ROW, strings.Repeat(" ", ScreenWidth))
+
+	stale := cluster.Stale(now)
+	dropped := cluster.Dropped.Load()
+	if cluster.Errors == 0 && !stale && cluster.Warning == nil && dropped == 0 {
+		return
+	}
+
+	x := 1
+	if stale {
+		SetColor("Red")
+		WriteAtS(x, STATUS_ROW, "STALE")
+		x += 6
+	}
+
+	var parts []string
+	if cluster.Errors > 0 || stale {
+		if cluster.Updated.IsZero() {
+			parts = append(parts, "no status yet")
+		} else {
+			parts = append(parts, fmt.Sprintf("last good status %s ago", now.Sub(cluster.Updated).Round(time.Second)))
+		}
+	}
+	if cluster.Errors > 0 {
+		parts = append(parts, fmt.Sprintf("%d poll errors, last %s ago: %v", cluster.Errors, now.Sub(cluster.LastErrorTime).Round(time.Second), cluster.LastError))
+	}
+	if cluster.Warning != nil {
+		parts = append(parts, cluster.Warning.Error())
+	}
+	if dropped > 0 {
+		parts = append(parts, fmt.Sprintf("%d statuses dropped, the screen being too slow", dropped))
+	}
+	text := strings.Join(parts, " | ")
+	SetColor("DarkYellow")
+	WriteAtS(x, STATUS_ROW, trimMax(text, ScreenWidth-x-1))
+}